@@ -0,0 +1,27 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// RegisterDebugConnsHandler wires GET /debug/conns?cid=N, which lists every live connection
+// the proxy is holding open for cluster cid: its id, resolved CLIENT SETNAME-style tag, and
+// frontend remote address. This makes it possible to identify which proxy-originated backend
+// connection corresponds to a given `CLIENT LIST` row during incident triage.
+func RegisterDebugConnsHandler(mux *http.ServeMux, p *Proxy) {
+	mux.HandleFunc("/debug/conns", func(w http.ResponseWriter, r *http.Request) {
+		cid, err := parseCid(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		conns := p.ListConns(cid)
+		if conns == nil {
+			http.Error(w, "unknown cluster", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(conns)
+	})
+}
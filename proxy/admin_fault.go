@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	errs "errors"
+)
+
+var errMissingCid = errs.New("admin: missing or invalid cid query parameter")
+
+// RegisterFaultAdminHandlers wires the chaos/fault-injection admin API onto mux. Every route
+// is keyed by the `cid` query parameter (the cluster id assigned by Proxy.addCluster), so an
+// operator can target a single live cluster without restarting the proxy:
+//
+//	POST /admin/fault?cid=0&op=delay&latency=50ms&jitter=20ms
+//	POST /admin/fault?cid=0&op=pause
+//	POST /admin/fault?cid=0&op=resume
+//	POST /admin/fault?cid=0&op=drop&probability=0.1
+//	POST /admin/fault?cid=0&op=corrupt-tx&mode=bitflip
+//	POST /admin/fault?cid=0&op=corrupt-rx&mode=off
+func RegisterFaultAdminHandlers(mux *http.ServeMux, p *Proxy) {
+	mux.HandleFunc("/admin/fault", func(w http.ResponseWriter, r *http.Request) {
+		cid, err := parseCid(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if p.getCluster(cid) == nil {
+			http.Error(w, "admin: unknown cluster", http.StatusNotFound)
+			return
+		}
+		injector := p.GetFaultInjector(cid)
+		if injector == nil {
+			injector = NewFaultInjector()
+			p.SetFaultInjector(cid, injector)
+		}
+		switch r.URL.Query().Get("op") {
+		case "delay":
+			latency, _ := time.ParseDuration(r.URL.Query().Get("latency"))
+			jitter, _ := time.ParseDuration(r.URL.Query().Get("jitter"))
+			injector.DelayAccept(latency, jitter)
+		case "pause":
+			injector.PauseAccept()
+		case "resume":
+			injector.ResumeAccept()
+		case "drop":
+			probability, _ := strconv.ParseFloat(r.URL.Query().Get("probability"), 64)
+			injector.DropAccept(probability)
+		case "corrupt-tx":
+			fn, err := parseCorruption(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			injector.ModifyTx(fn)
+		case "corrupt-rx":
+			fn, err := parseCorruption(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			injector.ModifyRx(fn)
+		default:
+			http.Error(w, "admin: op must be one of delay, pause, resume, drop, corrupt-tx, corrupt-rx", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// parseCorruption resolves the `mode` query parameter for op=corrupt-tx/corrupt-rx into the
+// byte-mutation func to install, or nil (mode=off) to clear a previously installed one.
+func parseCorruption(r *http.Request) (func([]byte) []byte, error) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "off" {
+		return nil, nil
+	}
+	fn := corruptionFunc(CorruptionPreset(mode))
+	if fn == nil {
+		return nil, errs.New("admin: mode must be one of bitflip, truncate, off")
+	}
+	return fn, nil
+}
+
+func parseCid(r *http.Request) (int32, error) {
+	cid, err := strconv.Atoi(r.URL.Query().Get("cid"))
+	if err != nil {
+		return 0, errMissingCid
+	}
+	return int32(cid), nil
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+
+	"overlord/pkg/types"
+	"overlord/proxy/proto"
+	rclstr "overlord/proxy/proto/redis/cluster"
+)
+
+// HashDistribution selects how a ClusterConfig's Servers are sharded across keys for the
+// standalone (non Redis Cluster) forwarders.
+const (
+	DistributionKetama     = "ketama"
+	DistributionModula     = "modula"
+	DistributionRendezvous = "rendezvous"
+)
+
+// Config is the top level overlord proxy configuration.
+type Config struct {
+	Proxy struct {
+		MaxConnections int32
+		ReadTimeout    int
+		WriteTimeout   int
+	}
+}
+
+// Validate checks c for values that would make NewProxy unsafe to run with.
+func (c *Config) Validate() error {
+	if c.Proxy.MaxConnections < 0 {
+		return errors.New("config: Proxy.MaxConnections must be >= 0")
+	}
+	if c.Proxy.ReadTimeout < 0 || c.Proxy.WriteTimeout < 0 {
+		return errors.New("config: Proxy.ReadTimeout/WriteTimeout must be >= 0")
+	}
+	return nil
+}
+
+// ClusterConfig describes one cluster the proxy listens for and forwards to.
+type ClusterConfig struct {
+	SN int32
+	ID int32
+
+	Name        string
+	ListenProto string
+	ListenAddr  string
+	CacheType   types.CacheType
+
+	HashMethod       string
+	HashDistribution string
+	HashTag          string
+
+	RedisAuth string
+
+	// ReadStrategy picks which node a read-only command is routed to for Redis Cluster
+	// (types.CacheTypeRedisCluster) clusters; see cluster.ReadStrategy. It is ignored by the
+	// standalone cache types, which have no replicas of their own to read from.
+	ReadStrategy string
+
+	// ClientName templates the CLIENT SETNAME sent on every connection this cluster opens, both
+	// toward the frontend's observability tag (see resolveClientName) and toward its backend
+	// NodeConnections pool. Empty falls back to DefaultClientNameTemplate.
+	ClientName string
+
+	DialTimeout  int
+	ReadTimeout  int
+	WriteTimeout int
+
+	NodeConnections int32
+	PingFailLimit   int32
+	PingAutoEject   bool
+	CloseWhenChange bool
+
+	Servers []string
+}
+
+// LoadClusterConf reads and parses the full ClusterConfig set from a JSON array at path.
+func LoadClusterConf(path string) ([]*ClusterConfig, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "LoadClusterConf read file")
+	}
+	var confs []*ClusterConfig
+	if err := json.Unmarshal(body, &confs); err != nil {
+		return nil, errors.Wrap(err, "LoadClusterConf parse json")
+	}
+	return confs, nil
+}
+
+// NewForwarder builds the proto.Forwarder for conf's CacheType: a slot-routing Forwarder for
+// Redis Cluster, or a Rendezvous-sharded forwarder for the standalone Memcache/Redis types.
+func NewForwarder(conf *ClusterConfig) (proto.Forwarder, error) {
+	if conf.CacheType == types.CacheTypeRedisCluster {
+		return rclstr.NewForwarder(rclstr.Config{
+			Servers:      conf.Servers,
+			ReadStrategy: rclstr.ReadStrategy(conf.ReadStrategy),
+			ClientName:   resolveClientName(conf.ClientName, conf.Name, "pool"),
+			PoolSize:     conf.NodeConnections,
+		})
+	}
+	return newShardForwarder(conf)
+}
@@ -0,0 +1,147 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"overlord/pkg/log"
+)
+
+// ConfigSource delivers the full set of cluster configurations whenever something changes, so
+// Proxy can diff it against what is currently running via parseChanged/addCluster. Watch must
+// emit the complete cluster set on every change, not a delta, and must close its channel once
+// ctx is done.
+type ConfigSource interface {
+	Watch(ctx context.Context) <-chan []*ClusterConfig
+}
+
+// FileConfigSource is the original ClusterConfFile poller, now expressed as a ConfigSource so
+// it can be swapped for a push-based source without touching Proxy's diff logic.
+type FileConfigSource struct {
+	Path     string
+	Interval time.Duration
+}
+
+// Watch implements ConfigSource.
+func (s *FileConfigSource) Watch(ctx context.Context) <-chan []*ClusterConfig {
+	var out = make(chan []*ClusterConfig)
+	go func() {
+		defer close(out)
+		var ticker = time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				confs, err := LoadClusterConf(s.Path)
+				if err != nil {
+					log.Errorf("failed to load conf file:%s, got error:%s\n", s.Path, err.Error())
+					atomic.AddInt32(&LoadFailCnt, 1)
+					continue
+				}
+				select {
+				case out <- confs:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// HTTPConfigSource long-polls a config endpoint, using ETag/If-None-Match so the server can
+// hold the request open until the config actually changes instead of proxies hammering it on
+// a fixed interval. A 304 response means "still current"; the source simply polls again.
+type HTTPConfigSource struct {
+	URL    string
+	Client *http.Client
+}
+
+// Watch implements ConfigSource.
+func (s *HTTPConfigSource) Watch(ctx context.Context) <-chan []*ClusterConfig {
+	var out = make(chan []*ClusterConfig)
+	var client = s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	go func() {
+		defer close(out)
+		var etag string
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+			if err != nil {
+				log.Errorf("http config source: bad request for url:%s, got error:%s\n", s.URL, err.Error())
+				return
+			}
+			if etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Errorf("http config source: request to url:%s failed, got error:%s\n", s.URL, err.Error())
+				atomic.AddInt32(&LoadFailCnt, 1)
+				if !errBackoff(ctx) {
+					return
+				}
+				continue
+			}
+			if resp.StatusCode == http.StatusNotModified {
+				_ = resp.Body.Close()
+				continue
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil || resp.StatusCode != http.StatusOK {
+				log.Errorf("http config source: bad response from url:%s, status:%d, got error:%v\n", s.URL, resp.StatusCode, err)
+				atomic.AddInt32(&LoadFailCnt, 1)
+				if !errBackoff(ctx) {
+					return
+				}
+				continue
+			}
+			var confs []*ClusterConfig
+			if err := json.Unmarshal(body, &confs); err != nil {
+				log.Errorf("http config source: failed to decode body from url:%s, got error:%s\n", s.URL, err.Error())
+				atomic.AddInt32(&LoadFailCnt, 1)
+				if !errBackoff(ctx) {
+					return
+				}
+				continue
+			}
+			etag = resp.Header.Get("ETag")
+			select {
+			case out <- confs:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// errBackoff is how long EtcdConfigSource/ConsulConfigSource/HTTPConfigSource pause before
+// retrying after a failed fetch, so a persistent outage (etcd/consul/the config endpoint down,
+// bad credentials, ...) doesn't spin the watch goroutine at full CPU re-issuing the same failing
+// call. It returns false if ctx was canceled while waiting, signalling the caller to stop.
+func errBackoff(ctx context.Context) bool {
+	select {
+	case <-time.After(time.Second):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
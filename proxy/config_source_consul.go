@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+
+	"overlord/pkg/log"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// ConsulConfigSource watches a Consul KV prefix using blocking queries, each key holding one
+// ClusterConfig as JSON. Like EtcdConfigSource, it trades the 1s ClusterConfFile poll interval
+// for near-immediate propagation.
+type ConsulConfigSource struct {
+	Client *consulapi.Client
+	Prefix string
+}
+
+// Watch implements ConfigSource.
+func (s *ConsulConfigSource) Watch(ctx context.Context) <-chan []*ClusterConfig {
+	var out = make(chan []*ClusterConfig)
+	go func() {
+		defer close(out)
+		var waitIndex uint64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			opts := (&consulapi.QueryOptions{WaitIndex: waitIndex}).WithContext(ctx)
+			pairs, meta, err := s.Client.KV().List(s.Prefix, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Errorf("consul config source: blocking query on prefix:%s failed, got error:%s\n", s.Prefix, err.Error())
+				if !errBackoff(ctx) {
+					return
+				}
+				continue
+			}
+			// WaitIndex going backward means Consul's index rolled over; reset to pick up
+			// the full current state again rather than blocking on a stale index forever.
+			if meta.LastIndex < waitIndex {
+				waitIndex = 0
+			} else {
+				waitIndex = meta.LastIndex
+			}
+			var confs = make([]*ClusterConfig, 0, len(pairs))
+			for _, pair := range pairs {
+				var conf ClusterConfig
+				if err := json.Unmarshal(pair.Value, &conf); err != nil {
+					log.Errorf("consul config source: bad value for key:%s, got error:%s\n", pair.Key, err.Error())
+					continue
+				}
+				confs = append(confs, &conf)
+			}
+			if !emit(ctx, out, confs) {
+				return
+			}
+		}
+	}()
+	return out
+}
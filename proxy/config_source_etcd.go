@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+
+	"overlord/pkg/log"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdConfigSource watches an etcd v3 key prefix for cluster configuration, each key holding
+// one ClusterConfig as JSON. It lets a multi-tenant deployment push config updates within
+// milliseconds instead of shipping files to every proxy host.
+type EtcdConfigSource struct {
+	Client *clientv3.Client
+	Prefix string
+}
+
+// Watch implements ConfigSource.
+func (s *EtcdConfigSource) Watch(ctx context.Context) <-chan []*ClusterConfig {
+	var out = make(chan []*ClusterConfig)
+	go func() {
+		defer close(out)
+		if confs, err := s.loadAll(ctx); err != nil {
+			log.Errorf("etcd config source: initial load of prefix:%s failed, got error:%s\n", s.Prefix, err.Error())
+		} else if !emit(ctx, out, confs) {
+			return
+		}
+		var watchCh = s.Client.Watch(ctx, s.Prefix, clientv3.WithPrefix())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watchCh:
+				if !ok {
+					return
+				}
+				confs, err := s.loadAll(ctx)
+				if err != nil {
+					log.Errorf("etcd config source: reload of prefix:%s failed, got error:%s\n", s.Prefix, err.Error())
+					if !errBackoff(ctx) {
+						return
+					}
+					continue
+				}
+				if !emit(ctx, out, confs) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+func (s *EtcdConfigSource) loadAll(ctx context.Context) ([]*ClusterConfig, error) {
+	resp, err := s.Client.Get(ctx, s.Prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	var confs = make([]*ClusterConfig, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var conf ClusterConfig
+		if err := json.Unmarshal(kv.Value, &conf); err != nil {
+			return nil, err
+		}
+		confs = append(confs, &conf)
+	}
+	return confs, nil
+}
+
+func emit(ctx context.Context, out chan<- []*ClusterConfig, confs []*ClusterConfig) bool {
+	select {
+	case out <- confs:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
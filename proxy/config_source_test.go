@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileConfigSourceWatchEmitsOnTick(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"a"}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &FileConfigSource{Path: path, Interval: 10 * time.Millisecond}
+	out := s.Watch(ctx)
+
+	select {
+	case confs := <-out:
+		if len(confs) != 1 || confs[0].Name != "a" {
+			t.Fatalf("Watch emitted %v, want one cluster named %q", confs, "a")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch never emitted")
+	}
+}
+
+func TestFileConfigSourceWatchClosesOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.json")
+	if err := os.WriteFile(path, []byte(`[]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &FileConfigSource{Path: path, Interval: time.Hour}
+	out := s.Watch(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("Watch sent a value instead of closing after ctx was canceled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch never closed its channel after ctx was canceled")
+	}
+}
+
+func TestHTTPConfigSourceWatchEmitsAndRetriesOnError(t *testing.T) {
+	var fail = true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", "v1")
+		_, _ = w.Write([]byte(`[{"name":"a"}]`))
+	}))
+	defer srv.Close()
+
+	// errBackoff sleeps a full second on the real clock; give the retried request more than
+	// one second to land rather than asserting on timing.
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	s := &HTTPConfigSource{URL: srv.URL}
+	out := s.Watch(ctx)
+
+	select {
+	case confs := <-out:
+		if len(confs) != 1 || confs[0].Name != "a" {
+			t.Fatalf("Watch emitted %v, want one cluster named %q", confs, "a")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Watch never emitted after a transient error")
+	}
+}
+
+func TestHTTPConfigSourceWatchNotModifiedSkipsEmit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &HTTPConfigSource{URL: srv.URL}
+	out := s.Watch(ctx)
+
+	select {
+	case confs := <-out:
+		t.Fatalf("Watch emitted %v on a 304, want nothing", confs)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestErrBackoffReturnsFalseOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if errBackoff(ctx) {
+		t.Fatal("errBackoff returned true with an already-canceled context")
+	}
+}
@@ -0,0 +1,257 @@
+package proxy
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// FaultInjector wraps the accept path of a single cluster so operators can blackbox-test
+// backend failover, backpressure and client retry behavior without touching real Redis or
+// Memcache servers. It is off (a no-op) until one of its Configure methods is called, and is
+// safe for concurrent use.
+//
+// The knobs are deliberately modelled after etcd's transport proxy: delaying/pausing/dropping
+// accepted connections, plus byte-level corruption of either direction of the stream.
+type FaultInjector struct {
+	mu sync.Mutex
+
+	delay, jitter time.Duration
+	dropProb      float64
+
+	paused   bool
+	resumeCh chan struct{}
+
+	modifyTx func([]byte) []byte
+	modifyRx func([]byte) []byte
+
+	stopped bool
+	stopCh  chan struct{}
+}
+
+// NewFaultInjector returns a FaultInjector with every fault disabled.
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{resumeCh: make(chan struct{}), stopCh: make(chan struct{})}
+}
+
+// Stop force-releases any connection currently parked in PauseAccept/DelayAccept inside
+// wrapAccept, and makes every future wrapAccept call drop immediately. Without this, an accept
+// goroutine blocked on an indefinite PauseAccept would never notice its cluster being removed:
+// drainConnections only counts connections that made it into Cluster.clientConns, so a
+// connection stuck here before that point would leak the goroutine and the held client fd
+// forever. Callers must call Stop before (or instead of) waiting on drainConnections.
+func (f *FaultInjector) Stop() {
+	f.mu.Lock()
+	if !f.stopped {
+		f.stopped = true
+		close(f.stopCh)
+	}
+	f.mu.Unlock()
+}
+
+// DelayAccept makes every future accepted connection sleep for latency, plus up to jitter of
+// extra random delay, before it is handed off to the proxy handler.
+func (f *FaultInjector) DelayAccept(latency, jitter time.Duration) {
+	f.mu.Lock()
+	f.delay, f.jitter = latency, jitter
+	f.mu.Unlock()
+}
+
+// PauseAccept stalls every new client connection until ResumeAccept is called.
+func (f *FaultInjector) PauseAccept() {
+	f.mu.Lock()
+	if !f.paused {
+		f.paused = true
+		f.resumeCh = make(chan struct{})
+	}
+	f.mu.Unlock()
+}
+
+// ResumeAccept releases connections previously stalled by PauseAccept.
+func (f *FaultInjector) ResumeAccept() {
+	f.mu.Lock()
+	if f.paused {
+		f.paused = false
+		close(f.resumeCh)
+	}
+	f.mu.Unlock()
+}
+
+// DropAccept closes a probability fraction ([0, 1]) of incoming connections immediately after
+// accept, before any bytes are exchanged with the client.
+func (f *FaultInjector) DropAccept(probability float64) {
+	f.mu.Lock()
+	f.dropProb = probability
+	f.mu.Unlock()
+}
+
+// ModifyTx installs fn to mutate every chunk of bytes written toward the client (the proxy's
+// egress direction). Pass nil to stop mutating. Only connections accepted while a ModifyTx or
+// ModifyRx hook was installed are affected (see wrapAccept).
+func (f *FaultInjector) ModifyTx(fn func([]byte) []byte) {
+	f.mu.Lock()
+	f.modifyTx = fn
+	f.mu.Unlock()
+}
+
+// ModifyRx installs fn to mutate every chunk of bytes read from the client (the proxy's
+// ingress direction). Pass nil to stop mutating. Only connections accepted while a ModifyTx or
+// ModifyRx hook was installed are affected (see wrapAccept).
+func (f *FaultInjector) ModifyRx(fn func([]byte) []byte) {
+	f.mu.Lock()
+	f.modifyRx = fn
+	f.mu.Unlock()
+}
+
+// CorruptionPreset names a canned byte-mutation usable with ModifyTx/ModifyRx. The admin HTTP
+// API can only select among fixed presets like these - a func([]byte) []byte can't cross the
+// wire - so this is the full set of corruption modes /admin/fault can drive.
+type CorruptionPreset string
+
+const (
+	// CorruptBitFlip flips every bit of the first byte of each chunk.
+	CorruptBitFlip CorruptionPreset = "bitflip"
+	// CorruptTruncate drops the back half of each chunk.
+	CorruptTruncate CorruptionPreset = "truncate"
+)
+
+// corruptionFunc resolves preset to the byte-mutation function ModifyTx/ModifyRx expects, or
+// nil if preset isn't recognized.
+func corruptionFunc(preset CorruptionPreset) func([]byte) []byte {
+	switch preset {
+	case CorruptBitFlip:
+		return bitFlip
+	case CorruptTruncate:
+		return truncate
+	default:
+		return nil
+	}
+}
+
+func bitFlip(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	out := make([]byte, len(b))
+	copy(out, b)
+	out[0] ^= 0xff
+	return out
+}
+
+func truncate(b []byte) []byte {
+	if len(b) <= 1 {
+		return b
+	}
+	return b[:len(b)/2]
+}
+
+// wrapAccept applies the pause/delay/drop faults to a freshly accepted connection. If it survives
+// and a ModifyTx or ModifyRx hook is installed at that moment, it is wrapped so the current hooks
+// keep mutating its bytes for the life of the conn; otherwise it is returned unwrapped and hooks
+// installed later do not affect it. It returns nil when the connection was dropped; the caller
+// must not use conn again in that case.
+func (f *FaultInjector) wrapAccept(conn net.Conn) net.Conn {
+	if !f.await() {
+		_ = conn.Close()
+		return nil
+	}
+	if d := f.nextDelay(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-f.stopCh:
+			timer.Stop()
+			_ = conn.Close()
+			return nil
+		}
+	}
+	if f.shouldDrop() {
+		_ = conn.Close()
+		return nil
+	}
+	return f.wrapConn(conn)
+}
+
+// await blocks while the injector is paused, returning true once it is safe to proceed and
+// false if Stop fired while waiting (or was already called), in which case the caller must
+// abandon the connection rather than use it.
+func (f *FaultInjector) await() bool {
+	for {
+		f.mu.Lock()
+		if f.stopped {
+			f.mu.Unlock()
+			return false
+		}
+		if !f.paused {
+			f.mu.Unlock()
+			return true
+		}
+		ch := f.resumeCh
+		f.mu.Unlock()
+		select {
+		case <-ch:
+		case <-f.stopCh:
+			return false
+		}
+	}
+}
+
+func (f *FaultInjector) nextDelay() time.Duration {
+	f.mu.Lock()
+	d, j := f.delay, f.jitter
+	f.mu.Unlock()
+	if j > 0 {
+		d += time.Duration(rand.Int63n(int64(j)))
+	}
+	return d
+}
+
+func (f *FaultInjector) shouldDrop() bool {
+	f.mu.Lock()
+	p := f.dropProb
+	f.mu.Unlock()
+	return p > 0 && rand.Float64() < p
+}
+
+func (f *FaultInjector) wrapConn(conn net.Conn) net.Conn {
+	f.mu.Lock()
+	tx, rx := f.modifyTx, f.modifyRx
+	f.mu.Unlock()
+	if tx == nil && rx == nil {
+		return conn
+	}
+	return &faultConn{Conn: conn, injector: f}
+}
+
+// faultConn wraps a net.Conn so libnet.NewConn keeps working unmodified while Read/Write
+// pass through the injector's current ModifyRx/ModifyTx hooks.
+type faultConn struct {
+	net.Conn
+	injector *FaultInjector
+}
+
+func (c *faultConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+	c.injector.mu.Lock()
+	rx := c.injector.modifyRx
+	c.injector.mu.Unlock()
+	if rx == nil {
+		return n, err
+	}
+	mutated := rx(p[:n])
+	return copy(p, mutated), err
+}
+
+func (c *faultConn) Write(p []byte) (int, error) {
+	c.injector.mu.Lock()
+	tx := c.injector.modifyTx
+	c.injector.mu.Unlock()
+	if tx != nil {
+		p = tx(p)
+	}
+	return c.Conn.Write(p)
+}
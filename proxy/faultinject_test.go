@@ -0,0 +1,169 @@
+package proxy
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFaultInjectorPassthroughByDefault(t *testing.T) {
+	injector := NewFaultInjector()
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan net.Conn, 1)
+	go func() { done <- injector.wrapAccept(server) }()
+
+	select {
+	case got := <-done:
+		if got == nil {
+			t.Fatal("wrapAccept dropped a connection with every fault disabled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wrapAccept blocked with every fault disabled")
+	}
+}
+
+func TestFaultInjectorPauseResume(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.PauseAccept()
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan net.Conn, 1)
+	go func() { done <- injector.wrapAccept(server) }()
+
+	select {
+	case <-done:
+		t.Fatal("wrapAccept returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	injector.ResumeAccept()
+	select {
+	case got := <-done:
+		if got == nil {
+			t.Fatal("wrapAccept dropped a connection after ResumeAccept")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wrapAccept never returned after ResumeAccept")
+	}
+}
+
+func TestFaultInjectorDropAccept(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.DropAccept(1)
+	_, server := net.Pipe()
+
+	if got := injector.wrapAccept(server); got != nil {
+		t.Fatalf("wrapAccept returned a connection with DropAccept(1), want nil")
+	}
+}
+
+func TestFaultInjectorDelayAccept(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.DelayAccept(50*time.Millisecond, 0)
+	_, server := net.Pipe()
+
+	start := time.Now()
+	if got := injector.wrapAccept(server); got == nil {
+		t.Fatal("wrapAccept dropped a connection with only DelayAccept set")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("wrapAccept returned after %s, want at least the configured 50ms delay", elapsed)
+	}
+}
+
+func TestFaultInjectorStopReleasesParkedAccept(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.PauseAccept()
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan net.Conn, 1)
+	go func() { done <- injector.wrapAccept(server) }()
+
+	select {
+	case <-done:
+		t.Fatal("wrapAccept returned before Stop or ResumeAccept")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	injector.Stop()
+	select {
+	case got := <-done:
+		if got != nil {
+			t.Fatal("wrapAccept returned a live connection after Stop, want nil (abandoned)")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not release a connection parked in PauseAccept")
+	}
+
+	// Stop must also make every future wrapAccept call drop immediately rather than pause.
+	_, server2 := net.Pipe()
+	if got := injector.wrapAccept(server2); got != nil {
+		t.Fatal("wrapAccept returned a connection after Stop, want nil")
+	}
+}
+
+func TestFaultInjectorModifyTxCorruptsWrites(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.ModifyTx(bitFlip)
+	client, server := net.Pipe()
+	defer client.Close()
+
+	wrapped := injector.wrapAccept(server)
+	if wrapped == nil {
+		t.Fatal("wrapAccept dropped a connection with only ModifyTx set")
+	}
+	defer wrapped.Close()
+
+	go func() { _, _ = wrapped.Write([]byte{0x00}) }()
+	buf := make([]byte, 1)
+	if _, err := client.Read(buf); err != nil {
+		t.Fatalf("client Read: %v", err)
+	}
+	if buf[0] != 0xff {
+		t.Fatalf("client read %#x, want the bit-flipped byte 0xff", buf[0])
+	}
+}
+
+func TestFaultInjectorModifyRxCorruptsReads(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.ModifyRx(bitFlip)
+	client, server := net.Pipe()
+	defer client.Close()
+
+	wrapped := injector.wrapAccept(server)
+	if wrapped == nil {
+		t.Fatal("wrapAccept dropped a connection with only ModifyRx set")
+	}
+	defer wrapped.Close()
+
+	go func() { _, _ = client.Write([]byte{0x00}) }()
+	buf := make([]byte, 1)
+	if _, err := wrapped.Read(buf); err != nil {
+		t.Fatalf("wrapped Read: %v", err)
+	}
+	if buf[0] != 0xff {
+		t.Fatalf("wrapped read %#x, want the bit-flipped byte 0xff", buf[0])
+	}
+}
+
+func TestCorruptionPresets(t *testing.T) {
+	if got := bitFlip([]byte{0x00, 0x01}); got[0] != 0xff || got[1] != 0x01 {
+		t.Fatalf("bitFlip = %v, want only the first byte flipped", got)
+	}
+	if got := bitFlip(nil); len(got) != 0 {
+		t.Fatalf("bitFlip(nil) = %v, want empty", got)
+	}
+	if got := truncate([]byte{1, 2, 3, 4}); len(got) != 2 {
+		t.Fatalf("truncate(len 4) = %v, want len 2", got)
+	}
+	if got := truncate([]byte{1}); len(got) != 1 {
+		t.Fatalf("truncate(len 1) = %v, want unchanged", got)
+	}
+	if corruptionFunc("bogus") != nil {
+		t.Fatal("corruptionFunc(\"bogus\") returned a non-nil func, want nil for an unrecognized preset")
+	}
+}
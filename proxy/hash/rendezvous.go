@@ -0,0 +1,114 @@
+// Package hash implements the key distribution strategies used by proxy forwarders to map a
+// cache key onto one of a cluster's live nodes.
+package hash
+
+import (
+	"hash/maphash"
+	"math"
+	"sort"
+)
+
+// Node is a single weighted member of a Rendezvous ring.
+type Node struct {
+	Name     string
+	Weight   float64
+	nameHash uint64
+}
+
+// Rendezvous implements Highest Random Weight (HRW) hashing as an alternative to ketama: for
+// each key it scores every live node and picks the maximum, so on node add/remove only 1/N of
+// keys move, matching ketama's stability without the 160-vnode ring memory. Lookup is O(N) but
+// branchless and cache-friendly, which tends to beat a ketama binary search for N <= ~64.
+//
+// A Rendezvous is not safe for concurrent Pick and Eject/Include calls; callers that reload or
+// auto-eject nodes at runtime must guard it with their own lock, the same as the ketama ring.
+type Rendezvous struct {
+	seed  maphash.Seed
+	nodes []Node
+	dead  map[string]bool
+}
+
+// processSeed is shared by every Rendezvous in the process so that rebuilding one (e.g. after a
+// config reload adds or removes a server) reproduces the same node/key scores for the nodes that
+// didn't change, keeping the "only 1/N of keys move" guarantee across rebuilds. maphash.Seed is
+// only valid within the process that created it, so this still differs from proxy to proxy -
+// fine, since HRW only needs node and key hashes to agree with each other on one proxy, not
+// across the fleet.
+var processSeed = maphash.MakeSeed()
+
+// NewRendezvous builds a Rendezvous over nodes. Nodes with a zero Weight are treated as having
+// weight 1, matching unweighted ketama.
+func NewRendezvous(nodes []Node) *Rendezvous {
+	r := &Rendezvous{seed: processSeed, dead: make(map[string]bool)}
+	r.nodes = make([]Node, len(nodes))
+	for i, n := range nodes {
+		if n.Weight == 0 {
+			n.Weight = 1
+		}
+		n.nameHash = mix(r.seed, n.Name)
+		r.nodes[i] = n
+	}
+	// sorting by name makes equal scores resolve the same way whatever order Servers were listed in
+	sort.Slice(r.nodes, func(i, j int) bool { return r.nodes[i].Name < r.nodes[j].Name })
+	return r
+}
+
+// Eject marks name as unavailable so PingAutoEject-style health checks can pull it out of
+// rotation without rebuilding the whole Rendezvous.
+func (r *Rendezvous) Eject(name string) {
+	r.dead[name] = true
+}
+
+// Include undoes a previous Eject, e.g. once a node starts passing pings again.
+func (r *Rendezvous) Include(name string) {
+	delete(r.dead, name)
+}
+
+// Pick returns the name of the live node with the highest score for key, per the standard
+// weighted HRW formulation: score = weight / -log(uniform_from_hash). It returns "" if every
+// node has been ejected.
+func (r *Rendezvous) Pick(key string) string {
+	var (
+		best      string
+		bestScore = math.Inf(-1)
+	)
+	for _, n := range r.nodes {
+		if r.dead[n.Name] {
+			continue
+		}
+		u := uniform(mix64(n.nameHash, mix(r.seed, key)))
+		score := n.Weight / -math.Log(u)
+		if score > bestScore {
+			bestScore, best = score, n.Name
+		}
+	}
+	return best
+}
+
+// mix hashes s with seed using the runtime's built-in string hasher: fast, and good enough to
+// decorrelate node-name and key hashes without pulling in an external xxhash/siphash dependency.
+func mix(seed maphash.Seed, s string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	_, _ = h.WriteString(s)
+	return h.Sum64()
+}
+
+// mix64 combines a node's precomputed name hash with a key hash using splitmix64's finalizer.
+func mix64(a, b uint64) uint64 {
+	x := a ^ (b + 0x9e3779b97f4a7c15 + (a << 6) + (a >> 2))
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// uniform maps a uint64 hash onto the half-open interval (0, 1], avoiding an exact 0 that would
+// blow up -log(u).
+func uniform(h uint64) float64 {
+	const maxUint64 = float64(1 << 64)
+	u := float64(h) / maxUint64
+	if u <= 0 {
+		u = 1.0 / maxUint64
+	}
+	return u
+}
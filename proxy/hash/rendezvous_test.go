@@ -0,0 +1,65 @@
+package hash
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRendezvousPickIsStable(t *testing.T) {
+	nodes := []Node{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}, {Name: "c", Weight: 1}}
+	r := NewRendezvous(nodes)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if first, second := r.Pick(key), r.Pick(key); first != second {
+			t.Fatalf("Pick(%q) is not deterministic: %q then %q", key, first, second)
+		}
+	}
+}
+
+func TestRendezvousOnlyFractionOfKeysMoveOnAdd(t *testing.T) {
+	before := NewRendezvous([]Node{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}, {Name: "c", Weight: 1}})
+	after := NewRendezvous([]Node{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}, {Name: "c", Weight: 1}, {Name: "d", Weight: 1}})
+
+	const total = 1000
+	var moved int
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if before.Pick(key) != after.Pick(key) {
+			moved++
+		}
+	}
+	// Adding a 4th of 4 equally-weighted nodes should move roughly 1/4 of keys; allow
+	// generous slack since this is a statistical property, not an exact guarantee.
+	if moved > total/2 {
+		t.Fatalf("adding a node moved %d/%d keys, want roughly 1/4 (well under half)", moved, total)
+	}
+}
+
+func TestRendezvousEjectExcludesNode(t *testing.T) {
+	r := NewRendezvous([]Node{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}})
+	r.Eject("a")
+	for i := 0; i < 100; i++ {
+		if got := r.Pick(fmt.Sprintf("key-%d", i)); got == "a" {
+			t.Fatalf("Pick returned ejected node %q", got)
+		}
+	}
+}
+
+func TestRendezvousIncludeUndoesEject(t *testing.T) {
+	r := NewRendezvous([]Node{{Name: "a", Weight: 1}})
+	r.Eject("a")
+	if got := r.Pick("key"); got != "" {
+		t.Fatalf("Pick with the only node ejected = %q, want \"\"", got)
+	}
+	r.Include("a")
+	if got := r.Pick("key"); got != "a" {
+		t.Fatalf("Pick after Include = %q, want %q", got, "a")
+	}
+}
+
+func TestRendezvousZeroWeightDefaultsToOne(t *testing.T) {
+	r := NewRendezvous([]Node{{Name: "a"}})
+	if got := r.Pick("key"); got != "a" {
+		t.Fatalf("Pick with a single zero-Weight node = %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"overlord/pkg/types"
+	mcbin "overlord/proxy/proto/memcache/binary"
+	"overlord/proxy/proto/redis"
+)
+
+// nodeConnPool keeps up to size connection streams open to every backend server a shardForwarder
+// picks keys across, handshaking each stream with CLIENT SETNAME (a no-op for the Memcache
+// binary protocol) the moment it is dialed.
+type nodeConnPool struct {
+	mu         sync.Mutex
+	size       int32
+	cacheType  types.CacheType
+	clientName string
+	streams    map[string][]net.Conn
+}
+
+func newNodeConnPool(size int32, cacheType types.CacheType, clientName string) *nodeConnPool {
+	if size <= 0 {
+		size = 1
+	}
+	return &nodeConnPool{size: size, cacheType: cacheType, clientName: clientName, streams: make(map[string][]net.Conn)}
+}
+
+// EnsureNodes dials size streams for every address in addrs the pool hasn't already opened
+// streams for.
+func (p *nodeConnPool) EnsureNodes(addrs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, addr := range addrs {
+		if _, ok := p.streams[addr]; ok {
+			continue
+		}
+		p.streams[addr] = p.dial(addr)
+	}
+}
+
+func (p *nodeConnPool) dial(addr string) []net.Conn {
+	var setName = p.setNameCmd()
+	var conns = make([]net.Conn, 0, p.size)
+	for i := int32(0); i < p.size; i++ {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err != nil {
+			continue
+		}
+		if len(setName) > 0 {
+			if _, err := conn.Write(setName); err != nil {
+				_ = conn.Close()
+				continue
+			}
+		}
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+func (p *nodeConnPool) setNameCmd() []byte {
+	switch p.cacheType {
+	case types.CacheTypeMemcacheBinary:
+		return mcbin.BuildClientSetNameCmd(p.clientName)
+	case types.CacheTypeRedis, types.CacheTypeRedisCluster:
+		return redis.BuildClientSetNameCmd(p.clientName)
+	default:
+		return nil
+	}
+}
+
+// Nodes returns every backend address the pool currently maintains streams for.
+func (p *nodeConnPool) Nodes() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var addrs = make([]string, 0, len(p.streams))
+	for addr := range p.streams {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Close closes every open stream.
+func (p *nodeConnPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conns := range p.streams {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}
+	p.streams = make(map[string][]net.Conn)
+}
@@ -0,0 +1,11 @@
+package proto
+
+// Forwarder owns the backend connections for one cluster and is shared by every frontend
+// connection currently being served by it. Proxy holds a reference with AddRef/Release; on a
+// config reload or cluster removal it calls Close on the old forwarder right away, without
+// waiting for the other references to be released.
+type Forwarder interface {
+	AddRef() int32
+	Release() int32
+	Close() error
+}
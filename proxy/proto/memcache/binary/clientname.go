@@ -0,0 +1,10 @@
+package binary
+
+// BuildClientSetNameCmd is the Memcache binary protocol's NOP-equivalent of Redis' CLIENT
+// SETNAME: the binary protocol has no connection-naming opcode, so there is nothing to send
+// on the wire. It exists so a NodeConnections pool can call the same handshake hook for every
+// cache type without a type switch at the call site; the name itself is still recorded against
+// the frontend connection for observability purposes.
+func BuildClientSetNameCmd(name string) []byte {
+	return nil
+}
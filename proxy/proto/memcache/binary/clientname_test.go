@@ -0,0 +1,9 @@
+package binary
+
+import "testing"
+
+func TestBuildClientSetNameCmdIsNop(t *testing.T) {
+	if got := BuildClientSetNameCmd("pool-1"); got != nil {
+		t.Fatalf("BuildClientSetNameCmd = %v, want nil since the binary protocol has no connection-naming opcode", got)
+	}
+}
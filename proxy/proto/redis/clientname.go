@@ -0,0 +1,20 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildClientSetNameCmd renders a RESP-encoded `CLIENT SETNAME name` command, so a
+// NodeConnections pool can prepend it as a handshake the first time it opens a backend
+// connection. name must not contain spaces or newlines, per Redis' own CLIENT SETNAME rules;
+// callers should sanitize a ClientName template's substitutions before calling this.
+func BuildClientSetNameCmd(name string) []byte {
+	var args = []string{"CLIENT", "SETNAME", name}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,19 @@
+package redis
+
+import "testing"
+
+func TestBuildClientSetNameCmd(t *testing.T) {
+	got := string(BuildClientSetNameCmd("pool-1"))
+	want := "*3\r\n$6\r\nCLIENT\r\n$7\r\nSETNAME\r\n$6\r\npool-1\r\n"
+	if got != want {
+		t.Fatalf("BuildClientSetNameCmd(%q) = %q, want %q", "pool-1", got, want)
+	}
+}
+
+func TestBuildClientSetNameCmdEmptyName(t *testing.T) {
+	got := string(BuildClientSetNameCmd(""))
+	want := "*3\r\n$6\r\nCLIENT\r\n$7\r\nSETNAME\r\n$0\r\n\r\n"
+	if got != want {
+		t.Fatalf("BuildClientSetNameCmd(\"\") = %q, want %q", got, want)
+	}
+}
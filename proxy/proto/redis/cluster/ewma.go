@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// ewmaAlpha weights how quickly a replica's tracked RTT reacts to a fresh PING sample. 0.2
+// gives roughly the last 5 samples meaningful weight, which smooths out a single slow PING
+// without making the selector blind to a replica that degrades over a few seconds.
+const ewmaAlpha = 0.2
+
+// rttEWMA is an exponentially weighted moving average of a single replica's PING round-trip
+// time, safe for concurrent readers and a single periodic writer.
+type rttEWMA struct {
+	mu     sync.RWMutex
+	value  time.Duration
+	primed bool
+}
+
+func (e *rttEWMA) observe(sample time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.primed {
+		e.value = sample
+		e.primed = true
+		return
+	}
+	e.value = time.Duration(ewmaAlpha*float64(sample) + (1-ewmaAlpha)*float64(e.value))
+}
+
+func (e *rttEWMA) get() time.Duration {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.value
+}
@@ -0,0 +1,152 @@
+package cluster
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config configures a Forwarder, translated from the proxy layer's ClusterConfig by
+// proxy.NewForwarder. It only carries primitive values so this package doesn't need to import
+// "overlord/proxy" (which imports this package to build a Forwarder in the first place).
+type Config struct {
+	// Servers seeds the node pool before the first CLUSTER SLOTS refresh; the first one is
+	// treated as the master of the whole key space until SetSlots replaces it with the real
+	// topology.
+	Servers []string
+
+	ReadStrategy ReadStrategy
+
+	// ClientName is the already-resolved CLIENT SETNAME argument for every backend stream this
+	// Forwarder's NodeConnPool opens.
+	ClientName string
+
+	// PoolSize is how many connection streams NodeConnPool keeps open per node.
+	PoolSize int32
+}
+
+// Forwarder is the proto.Forwarder for Redis Cluster: it owns the slot table, the read-replica
+// selector and the per-node connection pool, and resolves which backend address should serve
+// each command.
+type Forwarder struct {
+	mu       sync.RWMutex
+	slots    []*Slot
+	selector *ReplicaSelector
+	pool     *NodeConnPool
+
+	refCnt int32
+
+	stopCh chan struct{}
+}
+
+// NewForwarder builds a cluster Forwarder from conf. The slot table starts as a single slot
+// covering the whole key space, owned by the first seed server; callers should call SetSlots once CLUSTER SLOTS has been
+// fetched and parsed via ParseSlots so routing reflects the real cluster topology.
+//
+// When conf.ReadStrategy is ReadLatency, NewForwarder also starts a PING loop against every
+// known replica so ReplicaSelector.pickLowestLatency has a real rttEWMA to compare instead of
+// always falling through to replicas[0].
+func NewForwarder(conf Config) (*Forwarder, error) {
+	if len(conf.Servers) == 0 {
+		return nil, errors.New("cluster: at least one seed server is required")
+	}
+	var f = &Forwarder{
+		selector: NewReplicaSelector(conf.ReadStrategy),
+		pool:     NewNodeConnPool(conf.PoolSize, conf.ClientName),
+		slots:    []*Slot{{Start: 0, End: slotCount - 1, Master: conf.Servers[0]}},
+	}
+	f.pool.EnsureNodes(conf.Servers)
+	if conf.ReadStrategy == ReadLatency {
+		f.stopCh = make(chan struct{})
+		go f.pingReplicas()
+	}
+	return f, nil
+}
+
+// pingReplicas periodically TCP-dials every replica currently in the slot table and records the
+// round-trip time via Replica.ObservePing, the same way shardForwarder.pingLoop drives
+// Eject/Include for PingAutoEject.
+func (f *Forwarder) pingReplicas() {
+	var ticker = time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stopCh:
+			return
+		case <-ticker.C:
+			f.mu.RLock()
+			var slots = f.slots
+			f.mu.RUnlock()
+			for _, s := range slots {
+				for _, r := range s.Replicas {
+					pingReplica(r)
+				}
+			}
+		}
+	}
+}
+
+func pingReplica(r *Replica) {
+	var start = time.Now()
+	conn, err := net.DialTimeout("tcp", r.Addr, 200*time.Millisecond)
+	if err != nil {
+		return
+	}
+	_ = conn.Close()
+	r.ObservePing(time.Since(start))
+}
+
+// SetSlots installs a freshly parsed slot table (see ParseSlots), growing the connection pool to
+// cover every master and replica address it introduces.
+func (f *Forwarder) SetSlots(slots []*Slot) {
+	f.mu.Lock()
+	f.slots = slots
+	f.mu.Unlock()
+	for _, s := range slots {
+		var addrs = make([]string, 0, len(s.Replicas)+1)
+		addrs = append(addrs, s.Master)
+		for _, r := range s.Replicas {
+			addrs = append(addrs, r.Addr)
+		}
+		f.pool.EnsureNodes(addrs)
+	}
+}
+
+// Forward resolves which backend address should serve cmd against key: a replica chosen by the
+// configured ReadStrategy for a read-only command, or the owning slot's master otherwise.
+func (f *Forwarder) Forward(cmd, key string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var slot = Owner(f.slots, KeySlot(key))
+	if slot == nil {
+		return "", errors.New("cluster: no slot owner known for key:" + key)
+	}
+	if replica := f.selector.Pick(cmd, slot.Replicas); replica != nil {
+		return replica.Addr, nil
+	}
+	return slot.Master, nil
+}
+
+// Nodes returns every backend address the Forwarder currently maintains connection streams to,
+// for surfacing alongside each connection's observability tags.
+func (f *Forwarder) Nodes() []string {
+	return f.pool.Nodes()
+}
+
+func (f *Forwarder) AddRef() int32  { return atomic.AddInt32(&f.refCnt, 1) }
+func (f *Forwarder) Release() int32 { return atomic.AddInt32(&f.refCnt, -1) }
+
+func (f *Forwarder) Close() error {
+	if f.stopCh != nil {
+		select {
+		case <-f.stopCh:
+		default:
+			close(f.stopCh)
+		}
+	}
+	f.pool.Close()
+	return nil
+}
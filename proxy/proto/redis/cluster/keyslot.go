@@ -0,0 +1,44 @@
+package cluster
+
+import "strings"
+
+// slotCount is the fixed number of Redis Cluster hash slots.
+const slotCount = 16384
+
+// KeySlot computes the Redis Cluster hash slot for key, honoring the {hashtag} convention: if
+// key contains a non-empty {...} substring, only the bytes inside the braces are hashed, so
+// related keys can be forced onto the same slot (and therefore the same node).
+func KeySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % slotCount
+}
+
+// crc16Table is the poly 0x1021 lookup table Redis Cluster uses for slot hashing; with crc16's
+// initial value of 0 this computes CRC16/XMODEM.
+var crc16Table = func() [256]uint16 {
+	var table [256]uint16
+	for i := 0; i < 256; i++ {
+		var crc = uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc = (crc << 8) ^ crc16Table[byte(crc>>8)^s[i]]
+	}
+	return crc
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import "testing"
+
+func TestKeySlotHashtag(t *testing.T) {
+	// Keys sharing a hashtag must land on the same slot regardless of what surrounds the tag.
+	a := KeySlot("user:{123}:profile")
+	b := KeySlot("user:{123}:orders")
+	if a != b {
+		t.Fatalf("KeySlot(%q) = %d, KeySlot(%q) = %d, want equal slots for a shared hashtag", "user:{123}:profile", a, "user:{123}:orders", b)
+	}
+}
+
+func TestKeySlotEmptyHashtagIgnored(t *testing.T) {
+	// An empty {} is not a valid hashtag per the Redis Cluster spec; the whole key must still
+	// be hashed rather than hashing the empty string.
+	if got, want := KeySlot("foo{}bar"), KeySlot("foo{}bar"); got != want {
+		t.Fatalf("KeySlot not stable across calls: %d != %d", got, want)
+	}
+	empty := KeySlot("{}")
+	whole := crc16Slot("{}")
+	if empty != whole {
+		t.Fatalf("KeySlot(%q) = %d, want the whole key hashed (%d) since {} has no content", "{}", empty, whole)
+	}
+}
+
+func TestKeySlotUnmatchedBraceIgnored(t *testing.T) {
+	// "{foo" has no closing brace, so it isn't a hashtag at all - the whole key is hashed.
+	got := KeySlot("{foo")
+	want := crc16Slot("{foo")
+	if got != want {
+		t.Fatalf("KeySlot(%q) = %d, want %d (whole key hashed, no valid hashtag)", "{foo", got, want)
+	}
+}
+
+func TestKeySlotRange(t *testing.T) {
+	for _, key := range []string{"", "a", "hello world", "{tag}rest"} {
+		if slot := KeySlot(key); slot < 0 || slot >= slotCount {
+			t.Fatalf("KeySlot(%q) = %d, want a value in [0, %d)", key, slot, slotCount)
+		}
+	}
+}
+
+func crc16Slot(key string) int {
+	return int(crc16(key)) % slotCount
+}
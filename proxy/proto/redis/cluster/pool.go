@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"overlord/proxy/proto/redis"
+)
+
+// NodeConnPool keeps up to size connection streams open to every backend node address the
+// Forwarder knows about - master or replica alike, so a ReadReplica/ReadLatency strategy has its
+// own streams to read from instead of borrowing the master's. Every stream is handshaked with
+// CLIENT SETNAME the moment it is dialed, so the backend-side connection can be correlated back
+// to this proxy during incident triage.
+type NodeConnPool struct {
+	mu         sync.Mutex
+	size       int32
+	clientName string
+	streams    map[string][]net.Conn
+}
+
+// NewNodeConnPool builds an empty pool that will keep size streams open per node once EnsureNodes
+// is called. clientName is the already-resolved CLIENT SETNAME argument sent on every stream this
+// pool dials.
+func NewNodeConnPool(size int32, clientName string) *NodeConnPool {
+	if size <= 0 {
+		size = 1
+	}
+	return &NodeConnPool{size: size, clientName: clientName, streams: make(map[string][]net.Conn)}
+}
+
+// EnsureNodes dials size streams for every address in addrs the pool hasn't already opened
+// streams for - e.g. a replica address CLUSTER SLOTS just reported for the first time.
+func (p *NodeConnPool) EnsureNodes(addrs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, addr := range addrs {
+		if _, ok := p.streams[addr]; ok {
+			continue
+		}
+		p.streams[addr] = p.dial(addr)
+	}
+}
+
+func (p *NodeConnPool) dial(addr string) []net.Conn {
+	var setName = redis.BuildClientSetNameCmd(p.clientName)
+	var conns = make([]net.Conn, 0, p.size)
+	for i := int32(0); i < p.size; i++ {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err != nil {
+			continue
+		}
+		if len(setName) > 0 {
+			if _, err := conn.Write(setName); err != nil {
+				_ = conn.Close()
+				continue
+			}
+		}
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// StreamCount returns how many connection streams the pool currently holds open for addr.
+func (p *NodeConnPool) StreamCount(addr string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.streams[addr])
+}
+
+// Nodes returns every backend address the pool currently maintains streams for.
+func (p *NodeConnPool) Nodes() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var addrs = make([]string, 0, len(p.streams))
+	for addr := range p.streams {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Close closes every open stream.
+func (p *NodeConnPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conns := range p.streams {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}
+	p.streams = make(map[string][]net.Conn)
+}
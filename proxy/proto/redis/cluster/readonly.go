@@ -0,0 +1,50 @@
+package cluster
+
+import "strings"
+
+// readOnlyCmds is the static table of Redis commands that are safe to serve from a replica.
+// It intentionally only covers commands with no side effects on the keyspace; anything not
+// listed here (including commands we don't recognize) is routed to the slot's master.
+var readOnlyCmds = map[string]bool{
+	"GET":              true,
+	"MGET":             true,
+	"STRLEN":           true,
+	"GETRANGE":         true,
+	"EXISTS":           true,
+	"TTL":              true,
+	"PTTL":             true,
+	"TYPE":             true,
+	"HGET":             true,
+	"HMGET":            true,
+	"HGETALL":          true,
+	"HKEYS":            true,
+	"HVALS":            true,
+	"HLEN":             true,
+	"HEXISTS":          true,
+	"HSTRLEN":          true,
+	"LRANGE":           true,
+	"LLEN":             true,
+	"LINDEX":           true,
+	"SMEMBERS":         true,
+	"SCARD":            true,
+	"SISMEMBER":        true,
+	"SRANDMEMBER":      true,
+	"SDIFF":            true,
+	"SINTER":           true,
+	"SUNION":           true,
+	"ZRANGE":           true,
+	"ZREVRANGE":        true,
+	"ZRANGEBYSCORE":    true,
+	"ZREVRANGEBYSCORE": true,
+	"ZSCORE":           true,
+	"ZCARD":            true,
+	"ZCOUNT":           true,
+	"ZRANK":            true,
+	"ZREVRANK":         true,
+}
+
+// IsReadOnly reports whether cmd (case-insensitive) may be served by a replica. Writes and any
+// command absent from the table must still go to the slot's master.
+func IsReadOnly(cmd string) bool {
+	return readOnlyCmds[strings.ToUpper(cmd)]
+}
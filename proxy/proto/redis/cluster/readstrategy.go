@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ReadStrategy controls which node a read-only command for a slot is dispatched to.
+type ReadStrategy string
+
+// Supported ReadStrategy values. Writes, and any command IsReadOnly doesn't recognize, always
+// go to the slot's master regardless of the configured strategy.
+const (
+	// ReadMaster sends every command to the slot owner's master (the historical behavior).
+	ReadMaster ReadStrategy = "master"
+	// ReadReplica always serves reads from a replica, picked uniformly at random.
+	ReadReplica ReadStrategy = "replica"
+	// ReadLatency serves reads from whichever replica currently has the lowest EWMA PING RTT.
+	ReadLatency ReadStrategy = "latency"
+	// ReadRandom is an alias of ReadReplica kept for config readability.
+	ReadRandom ReadStrategy = "random"
+)
+
+// Replica is one read replica backing a slot, along with its tracked PING latency.
+type Replica struct {
+	Addr string
+
+	rtt rttEWMA
+}
+
+// ObservePing records a fresh PING RTT sample for the replica, feeding the EWMA used by the
+// "latency" ReadStrategy.
+func (r *Replica) ObservePing(rtt time.Duration) {
+	r.rtt.observe(rtt)
+}
+
+// ReplicaSelector picks which replica (if any) should serve a read-only command for a slot,
+// according to the cluster's configured ReadStrategy. It holds no connections itself: callers
+// resolve the returned address through their own NodeConnections pool.
+type ReplicaSelector struct {
+	strategy ReadStrategy
+	mu       sync.Mutex
+	rnd      *rand.Rand
+}
+
+// NewReplicaSelector builds a selector for the given strategy. An unrecognized strategy falls
+// back to ReadMaster so misconfiguration fails safe toward the existing master-only behavior.
+func NewReplicaSelector(strategy ReadStrategy) *ReplicaSelector {
+	switch strategy {
+	case ReadReplica, ReadLatency, ReadRandom:
+	default:
+		strategy = ReadMaster
+	}
+	return &ReplicaSelector{strategy: strategy, rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Pick returns the replica that should serve cmd for a slot whose live replicas are replicas,
+// or nil if the command must go to the master (unknown/write command, ReadMaster strategy, or
+// the slot currently has no replicas).
+func (s *ReplicaSelector) Pick(cmd string, replicas []*Replica) *Replica {
+	if s.strategy == ReadMaster || len(replicas) == 0 || !IsReadOnly(cmd) {
+		return nil
+	}
+	switch s.strategy {
+	case ReadLatency:
+		return s.pickLowestLatency(replicas)
+	default: // ReadReplica, ReadRandom
+		return s.pickRandom(replicas)
+	}
+}
+
+func (s *ReplicaSelector) pickRandom(replicas []*Replica) *Replica {
+	s.mu.Lock()
+	idx := s.rnd.Intn(len(replicas))
+	s.mu.Unlock()
+	return replicas[idx]
+}
+
+func (s *ReplicaSelector) pickLowestLatency(replicas []*Replica) *Replica {
+	best := replicas[0]
+	for _, r := range replicas[1:] {
+		if r.rtt.get() < best.rtt.get() {
+			best = r
+		}
+	}
+	return best
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import "testing"
+
+func TestNewReplicaSelectorUnrecognizedStrategyFallsBackToMaster(t *testing.T) {
+	s := NewReplicaSelector("bogus")
+	if s.strategy != ReadMaster {
+		t.Fatalf("NewReplicaSelector(%q).strategy = %q, want %q", "bogus", s.strategy, ReadMaster)
+	}
+}
+
+func TestReplicaSelectorPickMaster(t *testing.T) {
+	s := NewReplicaSelector(ReadMaster)
+	replicas := []*Replica{{Addr: "a"}, {Addr: "b"}}
+	if got := s.Pick("GET", replicas); got != nil {
+		t.Fatalf("Pick with ReadMaster = %v, want nil", got)
+	}
+}
+
+func TestReplicaSelectorPickNoReplicas(t *testing.T) {
+	s := NewReplicaSelector(ReadReplica)
+	if got := s.Pick("GET", nil); got != nil {
+		t.Fatalf("Pick with no replicas = %v, want nil", got)
+	}
+}
+
+func TestReplicaSelectorPickWriteCommandGoesToMaster(t *testing.T) {
+	s := NewReplicaSelector(ReadReplica)
+	replicas := []*Replica{{Addr: "a"}}
+	if got := s.Pick("SET", replicas); got != nil {
+		t.Fatalf("Pick(%q) with ReadReplica = %v, want nil since SET is not read-only", "SET", got)
+	}
+}
+
+func TestReplicaSelectorPickLowestLatency(t *testing.T) {
+	s := NewReplicaSelector(ReadLatency)
+	fast := &Replica{Addr: "fast"}
+	slow := &Replica{Addr: "slow"}
+	fast.rtt.observe(1)
+	slow.rtt.observe(100)
+
+	if got := s.Pick("GET", []*Replica{slow, fast}); got != fast {
+		t.Fatalf("Pick with ReadLatency = %v, want the lowest-RTT replica %v", got, fast)
+	}
+}
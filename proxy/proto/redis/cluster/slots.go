@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Slot is one contiguous hash-slot range owned by a master, together with every replica CLUSTER
+// SLOTS reported for it. Before this, only the master address was ever recorded, so
+// ReplicaSelector had no replicas to pick from no matter the configured ReadStrategy.
+type Slot struct {
+	Start, End int
+	Master     string
+	Replicas   []*Replica
+}
+
+// Owner returns the Slot owning hash slot idx, or nil if it isn't covered by slots.
+func Owner(slots []*Slot, idx int) *Slot {
+	for _, s := range slots {
+		if idx >= s.Start && idx <= s.End {
+			return s
+		}
+	}
+	return nil
+}
+
+// ParseSlots turns a decoded CLUSTER SLOTS reply into a Slot table. The expected shape, per
+// entry, is:
+//
+//	[startSlot int64, endSlot int64, [masterIP string, masterPort int64, masterID string, ...], [replicaIP, replicaPort, replicaID, ...], ...]
+//
+// decoding the RESP array itself is the frontend protocol decoder's job; ParseSlots only
+// understands the already-decoded Go value tree.
+func ParseSlots(reply []interface{}) ([]*Slot, error) {
+	var slots = make([]*Slot, 0, len(reply))
+	for _, raw := range reply {
+		entry, ok := raw.([]interface{})
+		if !ok || len(entry) < 3 {
+			return nil, fmt.Errorf("cluster: malformed CLUSTER SLOTS entry:%v", raw)
+		}
+		start, err := asInt(entry[0])
+		if err != nil {
+			return nil, fmt.Errorf("cluster: bad start slot:%w", err)
+		}
+		end, err := asInt(entry[1])
+		if err != nil {
+			return nil, fmt.Errorf("cluster: bad end slot:%w", err)
+		}
+		master, err := nodeAddr(entry[2])
+		if err != nil {
+			return nil, fmt.Errorf("cluster: bad master entry:%w", err)
+		}
+		var slot = &Slot{Start: start, End: end, Master: master}
+		for _, r := range entry[3:] {
+			addr, err := nodeAddr(r)
+			if err != nil {
+				return nil, fmt.Errorf("cluster: bad replica entry:%w", err)
+			}
+			slot.Replicas = append(slot.Replicas, &Replica{Addr: addr})
+		}
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+// nodeAddr renders a single CLUSTER SLOTS node entry ([ip, port, id, ...]) as "ip:port".
+func nodeAddr(raw interface{}) (string, error) {
+	node, ok := raw.([]interface{})
+	if !ok || len(node) < 2 {
+		return "", fmt.Errorf("malformed node entry:%v", raw)
+	}
+	ip, ok := node[0].(string)
+	if !ok {
+		return "", fmt.Errorf("node ip is not a string:%v", node[0])
+	}
+	port, err := asInt(node[1])
+	if err != nil {
+		return "", fmt.Errorf("bad node port:%w", err)
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(port)), nil
+}
+
+// ParseShards turns a decoded CLUSTER SHARDS reply into a Slot table - an alternative to
+// ParseSlots for clusters new enough to expose CLUSTER SHARDS, which groups possibly-disjoint
+// slot ranges under one shard entry instead of repeating the node list per range. The expected
+// shape, per shard entry, is the flat key/value array a RESP3 map decodes to:
+//
+//	["slots", [start0 int64, end0 int64, start1, end1, ...], "nodes", [nodeEntry, nodeEntry, ...]]
+//
+// and each nodeEntry is itself a flat key/value array, at minimum:
+//
+//	["ip", ipString, "port", portInt, "role", "master"|"replica", ...]
+//
+// decoding the RESP array/map itself is the frontend protocol decoder's job; ParseShards only
+// understands the already-decoded Go value tree.
+func ParseShards(reply []interface{}) ([]*Slot, error) {
+	var slots = make([]*Slot, 0, len(reply))
+	for _, raw := range reply {
+		entry, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cluster: malformed CLUSTER SHARDS entry:%v", raw)
+		}
+		fields, err := flatToMap(entry)
+		if err != nil {
+			return nil, fmt.Errorf("cluster: malformed CLUSTER SHARDS entry:%w", err)
+		}
+		ranges, ok := fields["slots"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cluster: CLUSTER SHARDS entry missing slots")
+		}
+		nodes, ok := fields["nodes"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cluster: CLUSTER SHARDS entry missing nodes")
+		}
+		master, replicas, err := shardNodes(nodes)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+1 < len(ranges); i += 2 {
+			start, err := asInt(ranges[i])
+			if err != nil {
+				return nil, fmt.Errorf("cluster: bad start slot:%w", err)
+			}
+			end, err := asInt(ranges[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("cluster: bad end slot:%w", err)
+			}
+			slots = append(slots, &Slot{Start: start, End: end, Master: master, Replicas: replicas})
+		}
+	}
+	return slots, nil
+}
+
+// shardNodes splits a CLUSTER SHARDS entry's node list into its master address and replica
+// list, keyed by each node's "role" field.
+func shardNodes(nodes []interface{}) (master string, replicas []*Replica, err error) {
+	for _, raw := range nodes {
+		entry, ok := raw.([]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("cluster: malformed CLUSTER SHARDS node:%v", raw)
+		}
+		fields, err := flatToMap(entry)
+		if err != nil {
+			return "", nil, fmt.Errorf("cluster: malformed CLUSTER SHARDS node:%w", err)
+		}
+		ip, _ := fields["ip"].(string)
+		port, err := asInt(fields["port"])
+		if err != nil {
+			return "", nil, fmt.Errorf("cluster: bad node port:%w", err)
+		}
+		var addr = net.JoinHostPort(ip, strconv.Itoa(port))
+		if role, _ := fields["role"].(string); role == "replica" {
+			replicas = append(replicas, &Replica{Addr: addr})
+		} else {
+			master = addr
+		}
+	}
+	if master == "" {
+		return "", nil, fmt.Errorf("cluster: CLUSTER SHARDS entry has no master node")
+	}
+	return master, replicas, nil
+}
+
+// flatToMap turns the flat key/value array a RESP3 map decodes to into a Go map, the same shape
+// ParseShards needs for both the shard entry itself and each of its node entries.
+func flatToMap(flat []interface{}) (map[string]interface{}, error) {
+	if len(flat)%2 != 0 {
+		return nil, fmt.Errorf("odd-length flat map:%v", flat)
+	}
+	var m = make(map[string]interface{}, len(flat)/2)
+	for i := 0; i+1 < len(flat); i += 2 {
+		key, ok := flat[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("non-string map key:%v", flat[i])
+		}
+		m[key] = flat[i+1]
+	}
+	return m, nil
+}
+
+// asInt accepts both int64 (the typical RESP integer decoding) and string (some decoders render
+// RESP integers as strings) so ParseSlots isn't tied to one RESP decoder's value representation.
+func asInt(raw interface{}) (int, error) {
+	switch v := raw.(type) {
+	case int64:
+		return int(v), nil
+	case int:
+		return v, nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("unexpected type %T", raw)
+	}
+}
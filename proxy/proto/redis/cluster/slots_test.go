@@ -0,0 +1,110 @@
+package cluster
+
+import "testing"
+
+func TestParseSlotsMalformedEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		reply []interface{}
+	}{
+		{"too short", []interface{}{[]interface{}{int64(0), int64(1)}}},
+		{"not an array", []interface{}{"not-an-entry"}},
+		{"bad start slot", []interface{}{[]interface{}{"not-a-slot", int64(1), []interface{}{"127.0.0.1", int64(7000), "id"}}}},
+		{"bad master entry", []interface{}{[]interface{}{int64(0), int64(1), "not-a-node"}}},
+		{"bad replica entry", []interface{}{[]interface{}{int64(0), int64(1), []interface{}{"127.0.0.1", int64(7000), "id"}, "not-a-node"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseSlots(tc.reply); err == nil {
+				t.Fatalf("ParseSlots(%v) = nil error, want an error for malformed input", tc.reply)
+			}
+		})
+	}
+}
+
+func TestParseSlotsMasterAndReplicas(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{
+			int64(0), int64(16383),
+			[]interface{}{"127.0.0.1", int64(7000), "master-id"},
+			[]interface{}{"127.0.0.1", int64(7001), "replica-id"},
+		},
+	}
+	slots, err := ParseSlots(reply)
+	if err != nil {
+		t.Fatalf("ParseSlots: %v", err)
+	}
+	if len(slots) != 1 {
+		t.Fatalf("ParseSlots returned %d slots, want 1", len(slots))
+	}
+	if slots[0].Master != "127.0.0.1:7000" {
+		t.Fatalf("Master = %q, want %q", slots[0].Master, "127.0.0.1:7000")
+	}
+	if len(slots[0].Replicas) != 1 || slots[0].Replicas[0].Addr != "127.0.0.1:7001" {
+		t.Fatalf("Replicas = %v, want one replica at 127.0.0.1:7001", slots[0].Replicas)
+	}
+}
+
+func TestParseShardsMasterAndReplicas(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{
+			"slots", []interface{}{int64(0), int64(8191), int64(12288), int64(16383)},
+			"nodes", []interface{}{
+				[]interface{}{"ip", "127.0.0.1", "port", int64(7000), "role", "master"},
+				[]interface{}{"ip", "127.0.0.1", "port", int64(7001), "role", "replica"},
+			},
+		},
+	}
+	slots, err := ParseShards(reply)
+	if err != nil {
+		t.Fatalf("ParseShards: %v", err)
+	}
+	// Two disjoint ranges sharing one set of nodes must yield two Slot entries.
+	if len(slots) != 2 {
+		t.Fatalf("ParseShards returned %d slots, want 2", len(slots))
+	}
+	for _, s := range slots {
+		if s.Master != "127.0.0.1:7000" {
+			t.Fatalf("Master = %q, want %q", s.Master, "127.0.0.1:7000")
+		}
+		if len(s.Replicas) != 1 || s.Replicas[0].Addr != "127.0.0.1:7001" {
+			t.Fatalf("Replicas = %v, want one replica at 127.0.0.1:7001", s.Replicas)
+		}
+	}
+	if slots[0].Start != 0 || slots[0].End != 8191 || slots[1].Start != 12288 || slots[1].End != 16383 {
+		t.Fatalf("unexpected slot ranges: %+v, %+v", slots[0], slots[1])
+	}
+}
+
+func TestParseShardsMalformedEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		reply []interface{}
+	}{
+		{"missing slots", []interface{}{[]interface{}{"nodes", []interface{}{}}}},
+		{"missing nodes", []interface{}{[]interface{}{"slots", []interface{}{int64(0), int64(1)}}}},
+		{"no master node", []interface{}{
+			[]interface{}{
+				"slots", []interface{}{int64(0), int64(1)},
+				"nodes", []interface{}{[]interface{}{"ip", "127.0.0.1", "port", int64(7001), "role", "replica"}},
+			},
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseShards(tc.reply); err == nil {
+				t.Fatalf("ParseShards(%v) = nil error, want an error for malformed input", tc.reply)
+			}
+		})
+	}
+}
+
+func TestOwner(t *testing.T) {
+	slots := []*Slot{{Start: 0, End: 99, Master: "a"}, {Start: 100, End: 199, Master: "b"}}
+	if owner := Owner(slots, 150); owner == nil || owner.Master != "b" {
+		t.Fatalf("Owner(150) = %v, want slot owned by %q", owner, "b")
+	}
+	if owner := Owner(slots, 200); owner != nil {
+		t.Fatalf("Owner(200) = %v, want nil for an uncovered slot", owner)
+	}
+}
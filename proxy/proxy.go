@@ -1,10 +1,12 @@
 package proxy
 
 import (
+	"context"
 	errs "errors"
 	"net"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,22 +26,45 @@ import (
 // proxy errors
 var (
 	ErrProxyMoreMaxConns              = errs.New("Proxy accept more than max connextions")
+	ErrProxyMaxClusterCnt             = errs.New("Proxy cluster count reached MaxClusterCnt")
 	ClusterSn                   int32 = 0
 	MonitorCfgIntervalMilliSecs int   = 10 * 100 // Time interval to monitor config change
+	DrainTimeoutMilliSecs       int   = 5 * 1000 // Time a draining cluster waits for in-flight conns before force close
 	ClusterChangeCount          int32 = 0
 	ClusterConfChangeFailCnt    int32 = 0
 	AddClusterFailCnt           int32 = 0
 	LoadFailCnt                 int32 = 0
-	FailedDueToRemovedCnt       int32 = 0
+	ClusterDrainedCnt           int32 = 0
+	ClusterDrainFailCnt         int32 = 0
 )
 
 const MaxClusterCnt int32 = 128
 
+// DefaultClientNameTemplate is the ClientName template used when ClusterConfig.ClientName is
+// left empty. {cluster} is substituted with the cluster name, and {frontaddr} with the frontend
+// client's remote address for a frontend connection's tag, or with the literal "pool" for the
+// CLIENT SETNAME sent on the backend pool's streams.
+const DefaultClientNameTemplate = "overlord-{cluster}-{frontaddr}"
+
+// resolveClientName expands a ClientName template for one frontend connection, so it can be
+// correlated with a `CLIENT LIST` row on the Redis side during incident triage.
+func resolveClientName(tpl, cluster, frontAddr string) string {
+	if tpl == "" {
+		tpl = DefaultClientNameTemplate
+	}
+	var r = strings.NewReplacer("{cluster}", cluster, "{frontaddr}", frontAddr)
+	return r.Replace(tpl)
+}
+
 type Cluster struct {
-	conf        *ClusterConfig
-	clientConns map[int64]*libnet.Conn
-	forwarder   proto.Forwarder
-	mutex       sync.Mutex
+	conf          *ClusterConfig
+	clientConns   map[int64]*libnet.Conn
+	connNames     map[int64]string
+	forwarder     proto.Forwarder
+	faultInjector *FaultInjector
+	listener      net.Listener
+	draining      bool
+	mutex         sync.Mutex
 }
 
 // Proxy is proxy.
@@ -47,12 +72,22 @@ type Proxy struct {
 	c               *Config
 	ClusterConfFile string // cluster configure file name
 
-	clusters      [MaxClusterCnt]*Cluster
-	CurClusterCnt int32
-	once          sync.Once
+	// ConfigSource overrides how Proxy learns about cluster configuration changes. When nil,
+	// Serve falls back to a FileConfigSource polling ClusterConfFile every
+	// MonitorCfgIntervalMilliSecs, matching the historical behavior.
+	ConfigSource ConfigSource
+
+	clusters       map[int32]*Cluster
+	freeClusterIDs []int32
+	nextClusterID  int32
+	CurClusterCnt  int32
+	once           sync.Once
 
 	conns int32
 
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	lock   sync.Mutex
 	closed bool
 }
@@ -65,6 +100,8 @@ func NewProxy(c *Config) (p *Proxy, err error) {
 	}
 	p = &Proxy{}
 	p.c = c
+	p.clusters = make(map[int32]*Cluster)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
 	return
 }
 
@@ -79,7 +116,6 @@ func (p *Proxy) Serve(ccs []*ClusterConfig) {
 		if len(ccs) == 0 {
 			log.Warnf("overlord will never listen on any port due to cluster is not specified")
 		}
-		p.CurClusterCnt = 0
 		for _, conf := range ccs {
 			var err = p.addCluster(conf)
 			if err != nil {
@@ -92,30 +128,52 @@ func (p *Proxy) Serve(ccs []*ClusterConfig) {
 	})
 }
 
+// allocClusterID hands out a cluster slot, preferring one freed by a previous removal so
+// MaxClusterCnt isn't permanently consumed by clusters that came and went across reloads.
+// Callers must hold p.lock.
+func (p *Proxy) allocClusterID() (int32, error) {
+	if n := len(p.freeClusterIDs); n > 0 {
+		var id = p.freeClusterIDs[n-1]
+		p.freeClusterIDs = p.freeClusterIDs[:n-1]
+		return id, nil
+	}
+	if p.nextClusterID >= MaxClusterCnt {
+		return 0, ErrProxyMaxClusterCnt
+	}
+	var id = p.nextClusterID
+	p.nextClusterID++
+	return id, nil
+}
+
 func (p *Proxy) addCluster(newConf *ClusterConfig) error {
 	newConf.SN = genClusterSn()
 	p.lock.Lock()
-	var clusterID = p.CurClusterCnt
+	var clusterID, idErr = p.allocClusterID()
+	if idErr != nil {
+		p.lock.Unlock()
+		return idErr
+	}
 	newConf.ID = clusterID
 	var newForwarder, err = NewForwarder(newConf)
 	if err != nil {
+		p.freeClusterIDs = append(p.freeClusterIDs, clusterID)
 		p.lock.Unlock()
 		return err
 	}
 	newForwarder.AddRef()
 	var cluster = &Cluster{conf: newConf, forwarder: newForwarder}
 	cluster.clientConns = make(map[int64]*libnet.Conn)
+	cluster.connNames = make(map[int64]string)
 	p.clusters[clusterID] = cluster
 	var servErr = p.serve(clusterID)
 	if servErr != nil {
-		p.clusters[clusterID] = nil
+		delete(p.clusters, clusterID)
+		p.freeClusterIDs = append(p.freeClusterIDs, clusterID)
 		p.lock.Unlock()
 		cluster.Close()
-		cluster.forwarder = nil
-		newForwarder.Release()
 		return servErr
 	}
-	p.CurClusterCnt++
+	p.CurClusterCnt = int32(len(p.clusters))
 	p.lock.Unlock()
 	log.Infof("succeed to add cluster:%s with addr:%s\n", newConf.Name, newConf.ListenAddr)
 	return nil
@@ -129,6 +187,7 @@ func (p *Proxy) serve(cid int32) error {
 		log.Errorf("failed to listen on address:%s, got error:%s\n", conf.ListenAddr, err.Error())
 		return err
 	}
+	p.clusters[cid].setListener(l)
 	log.Infof("overlord proxy cluster[%s] addr(%s) start listening", conf.Name, conf.ListenAddr)
 	go p.accept(cid, l)
 	return nil
@@ -136,19 +195,37 @@ func (p *Proxy) serve(cid int32) error {
 
 func (p *Proxy) accept(cid int32, l net.Listener) {
 	for {
-		var conf = p.getClusterConf(cid)
-		if p.closed {
-			log.Infof("overlord proxy cluster[%s] addr(%s) stop listen", conf.Name, conf.ListenAddr)
-			return
-		}
 		conn, err := l.Accept()
 		if err != nil {
+			// l is the specific listener this goroutine was handed at serve() time. Checking
+			// for it being closed directly - rather than re-fetching the cluster by cid and
+			// asking whether it is draining - keeps this correct even if cid has since been
+			// reused by a brand new cluster on a brand new listener: that new cluster is not
+			// draining, so the old check would have looped here forever logging accept errors
+			// on a listener nothing can ever read from again.
+			if errs.Is(err, net.ErrClosed) {
+				log.Infof("overlord proxy cluster(cid:%d) listener closed, accept loop exiting", cid)
+				return
+			}
 			if conn != nil {
 				_ = conn.Close()
 			}
-			log.Errorf("cluster(%s) addr(%s) accept connection error:%+v", conf.Name, conf.ListenAddr, err)
+			log.Errorf("cluster(cid:%d) accept connection error:%+v", cid, err)
 			continue
 		}
+		var cluster = p.getCluster(cid)
+		if cluster == nil || p.closed {
+			_ = conn.Close()
+			return
+		}
+		var conf = cluster.getConf()
+		if injector := p.GetFaultInjector(cid); injector != nil {
+			conn = injector.wrapAccept(conn)
+			if conn == nil {
+				// DropAccept fired or the injector was stopped; the connection has already been closed.
+				continue
+			}
+		}
 		if p.c.Proxy.MaxConnections > 0 {
 			if conns := atomic.LoadInt32(&p.conns); conns > p.c.Proxy.MaxConnections {
 				// cache type
@@ -178,7 +255,8 @@ func (p *Proxy) accept(cid int32, l net.Listener) {
 		}
 		atomic.AddInt32(&p.conns, 1)
 		var frontConn = libnet.NewConn(conn, time.Second*time.Duration(p.c.Proxy.ReadTimeout), time.Second*time.Duration(p.c.Proxy.WriteTimeout))
-		err = p.addConnection(cid, conf.SN, frontConn)
+		var clientName = resolveClientName(conf.ClientName, conf.Name, conn.RemoteAddr().String())
+		err = p.addConnection(cid, conf.SN, frontConn, clientName)
 		if err != nil {
 			// corner case, configure changed when we try to keep this connection
 			log.Errorf("corner case, configure just changed when after accept a connection, got error:%s\n", err.Error())
@@ -196,59 +274,138 @@ func (p *Proxy) Close() error {
 	if p.closed {
 		return nil
 	}
-	for i := 0; i < int(p.CurClusterCnt); i++ {
-		p.clusters[i].Close()
+	p.cancel()
+	for _, cluster := range p.clusters {
+		cluster.Close()
 	}
 	p.closed = true
 	return nil
 }
 
+// getCluster looks up the cluster currently assigned to cid, thread safe. It returns nil once
+// the cluster has been removed (e.g. drained away during a hot reload), which callers must
+// treat as "nothing left to do here" rather than a programming error.
+func (p *Proxy) getCluster(cid int32) *Cluster {
+	p.lock.Lock()
+	var cluster = p.clusters[cid]
+	p.lock.Unlock()
+	return cluster
+}
+
 // Get forwarder from proxy, thread safe
-func (p *Proxy) addConnection(cid int32, sn int32, conn *libnet.Conn) error {
-	var ret = p.clusters[cid].addConnection(sn, conn)
-	return ret
+func (p *Proxy) addConnection(cid int32, sn int32, conn *libnet.Conn, clientName string) error {
+	var cluster = p.getCluster(cid)
+	if cluster == nil {
+		return errors.New("overlord: cluster(" + strconv.Itoa(int(cid)) + ") no longer exists")
+	}
+	return cluster.addConnection(sn, conn, clientName)
+}
+
+// ListConns returns observability tags for every live connection of cluster cid, for surfacing
+// in slow-log entries and the /debug/conns admin endpoint.
+func (p *Proxy) ListConns(cid int32) []ConnInfo {
+	if cluster := p.getCluster(cid); cluster != nil {
+		return cluster.listConns()
+	}
+	return nil
 }
 
 func (p *Proxy) RemoveConnection(cid int32, connID int64) {
-	p.clusters[cid].removeConnection(connID)
+	if cluster := p.getCluster(cid); cluster != nil {
+		cluster.removeConnection(connID)
+	}
 }
 
 func (p *Proxy) CloseAndRemoveConnection(cid int32, connID int64) {
-	p.clusters[cid].closeAndRemoveConnection(connID)
+	if cluster := p.getCluster(cid); cluster != nil {
+		cluster.closeAndRemoveConnection(connID)
+	}
 }
 
 func (p *Proxy) CloseAllConnections(cid int32) {
-	p.clusters[cid].closeAllConnections()
+	if cluster := p.getCluster(cid); cluster != nil {
+		cluster.closeAllConnections()
+	}
 }
 
 // Get forwarder from proxy, thread safe
 func (p *Proxy) GetForwarder(cid int32) proto.Forwarder {
-	return p.clusters[cid].getForwarder()
+	return p.getCluster(cid).getForwarder()
 }
 
 // Get forwarder from proxy, thread safe
 func (p *Proxy) getClusterConf(cid int32) *ClusterConfig {
-	return p.clusters[cid].getConf()
+	return p.getCluster(cid).getConf()
 }
 
-func (p *Proxy) anyClusterRemoved(newConfs, oldConfs []*ClusterConfig) bool {
-	var (
-		newNames = make(map[string]int)
-		oldNames = make(map[string]int)
-	)
+// GetFaultInjector returns the chaos/fault-injection hook attached to cluster cid, or nil
+// if fault injection has never been enabled for it.
+func (p *Proxy) GetFaultInjector(cid int32) *FaultInjector {
+	if cluster := p.getCluster(cid); cluster != nil {
+		return cluster.getFaultInjector()
+	}
+	return nil
+}
+
+// SetFaultInjector attaches (or replaces) the fault injector for cluster cid. Passing nil
+// disables fault injection again.
+func (p *Proxy) SetFaultInjector(cid int32, injector *FaultInjector) {
+	if cluster := p.getCluster(cid); cluster != nil {
+		cluster.setFaultInjector(injector)
+	}
+}
+
+// removedClusters returns the old cluster configs that no longer appear in newConfs by name,
+// i.e. the clusters a hot reload should drain and remove.
+func (p *Proxy) removedClusters(newConfs, oldConfs []*ClusterConfig) []*ClusterConfig {
+	var newNames = make(map[string]int)
 	for _, conf := range newConfs {
 		newNames[conf.Name] = 1
 	}
+	var removed []*ClusterConfig
 	for _, conf := range oldConfs {
-		oldNames[conf.Name] = 1
-	}
-	for name, _ := range oldNames {
-		_, find := newNames[name]
-		if !find {
-			return true
+		if _, find := newNames[conf.Name]; !find {
+			removed = append(removed, conf)
 		}
 	}
-	return false
+	return removed
+}
+
+// removeCluster drains cluster conf.ID: it stops accepting new connections immediately, gives
+// in-flight connections up to DrainTimeoutMilliSecs to finish on their own, then force-closes
+// whatever is left and releases the forwarder. The cluster id is returned to the free list so
+// a later reload can reuse it instead of permanently consuming a MaxClusterCnt slot.
+func (p *Proxy) removeCluster(conf *ClusterConfig) {
+	p.lock.Lock()
+	var cluster, ok = p.clusters[conf.ID]
+	if !ok {
+		p.lock.Unlock()
+		return
+	}
+	delete(p.clusters, conf.ID)
+	p.freeClusterIDs = append(p.freeClusterIDs, conf.ID)
+	p.CurClusterCnt = int32(len(p.clusters))
+	p.lock.Unlock()
+
+	cluster.markDraining()
+	if l := cluster.getListener(); l != nil {
+		_ = l.Close()
+	}
+	// Force-release any accept goroutine parked in the fault injector (e.g. an indefinite
+	// PauseAccept) before waiting on drainConnections: a connection stuck there never reached
+	// clientConns, so drainConnections has no way to see it, and without Stop that goroutine
+	// and its client fd would stay parked after the cluster is gone.
+	if injector := cluster.getFaultInjector(); injector != nil {
+		injector.Stop()
+	}
+	if cluster.drainConnections(time.Duration(DrainTimeoutMilliSecs) * time.Millisecond) {
+		log.Infof("overlord proxy cluster[%s] addr(%s) drained cleanly before removal\n", conf.Name, conf.ListenAddr)
+	} else {
+		atomic.AddInt32(&ClusterDrainFailCnt, 1)
+		log.Warnf("overlord proxy cluster[%s] addr(%s) drain timed out, force closing remaining connections\n", conf.Name, conf.ListenAddr)
+	}
+	cluster.Close()
+	atomic.AddInt32(&ClusterDrainedCnt, 1)
 }
 
 func (p *Proxy) parseChanged(newConfs, oldConfs []*ClusterConfig) (changed, newAdd []*ClusterConfig) {
@@ -289,72 +446,88 @@ func (p *Proxy) parseChanged(newConfs, oldConfs []*ClusterConfig) (changed, newA
 }
 
 func (p *Proxy) monitorConfChange() {
-	for {
-		time.Sleep(time.Duration(MonitorCfgIntervalMilliSecs) * time.Millisecond)
-		var newConfs, err = LoadClusterConf(p.ClusterConfFile)
-		if err != nil {
-			log.Errorf("failed to load conf file:%s, got error:%s\n", p.ClusterConfFile, err.Error())
-			atomic.AddInt32(&LoadFailCnt, 1)
-			continue
-		}
-		var oldConfs []*ClusterConfig
-		for i := int32(0); i < p.CurClusterCnt; i++ {
-			var conf = p.clusters[i].getConf()
-			oldConfs = append(oldConfs, conf)
-		}
-		var removed = p.anyClusterRemoved(newConfs, oldConfs)
-		if removed {
-			log.Errorf("some cluster is removed from conf file, ignore this change")
-			atomic.AddInt32(&FailedDueToRemovedCnt, 1)
-			continue
+	var source = p.ConfigSource
+	if source == nil {
+		source = &FileConfigSource{
+			Path:     p.ClusterConfFile,
+			Interval: time.Duration(MonitorCfgIntervalMilliSecs) * time.Millisecond,
 		}
+	}
+	for newConfs := range source.Watch(p.ctx) {
+		p.applyConfs(newConfs)
+	}
+}
+
+// applyConfs diffs a freshly observed cluster set against what is currently running and
+// applies the change: clusters missing from newConfs are drained and removed, changed ones get
+// a new forwarder, and new ones are added. A ConfigSource only decides when and how newConfs is
+// produced, not what happens with it.
+func (p *Proxy) applyConfs(newConfs []*ClusterConfig) {
+	p.lock.Lock()
+	var oldConfs = make([]*ClusterConfig, 0, len(p.clusters))
+	for _, cluster := range p.clusters {
+		oldConfs = append(oldConfs, cluster.getConf())
+	}
+	p.lock.Unlock()
+
+	for _, conf := range p.removedClusters(newConfs, oldConfs) {
+		p.removeCluster(conf)
+	}
 
-		var newAdd []*ClusterConfig
-		var changedConf []*ClusterConfig
-		changedConf, newAdd = p.parseChanged(newConfs, oldConfs)
+	var newAdd []*ClusterConfig
+	var changedConf []*ClusterConfig
+	changedConf, newAdd = p.parseChanged(newConfs, oldConfs)
 
-		var clusterCnt = p.CurClusterCnt + int32(len(newAdd))
+	var clusterCnt = p.CurClusterCnt + int32(len(newAdd))
 
-		if clusterCnt > MaxClusterCnt {
-			log.Errorf("failed to reload conf as too much cluster will be added, new cluster count(%d) and max count(%d)",
-				clusterCnt, MaxClusterCnt)
+	if clusterCnt > MaxClusterCnt {
+		log.Errorf("failed to reload conf as too much cluster will be added, new cluster count(%d) and max count(%d)",
+			clusterCnt, MaxClusterCnt)
+		return
+	}
+	for _, conf := range changedConf {
+		// use new forwarder now
+		var cluster = p.getCluster(conf.ID)
+		if cluster == nil {
 			continue
 		}
-		for _, conf := range changedConf {
-			// use new forwarder now
-			var err = p.clusters[conf.ID].processConfChange(conf)
-			if err == nil {
-				atomic.AddInt32(&ClusterChangeCount, 1)
-				log.Infof("succeed to change conf of cluster(%s:%d)\n", conf.Name, conf.ID)
-				continue
-			}
-			atomic.AddInt32(&ClusterConfChangeFailCnt, 1)
-			log.Errorf("failed to change conf of cluster(%s), got error:%s\n", conf.Name, err.Error())
+		var err = cluster.processConfChange(conf)
+		if err == nil {
+			atomic.AddInt32(&ClusterChangeCount, 1)
+			log.Infof("succeed to change conf of cluster(%s:%d)\n", conf.Name, conf.ID)
+			continue
 		}
-		for _, conf := range newAdd {
-			var err = p.addCluster(conf)
-			if err != nil {
-				atomic.AddInt32(&AddClusterFailCnt, 1)
-				log.Errorf("failed to add new cluster:%s, got error:%s\n", conf.Name, err.Error())
-				continue
-			}
-			log.Infof("succeed to add new cluster:%s", conf.Name)
+		atomic.AddInt32(&ClusterConfChangeFailCnt, 1)
+		log.Errorf("failed to change conf of cluster(%s), got error:%s\n", conf.Name, err.Error())
+	}
+	for _, conf := range newAdd {
+		var err = p.addCluster(conf)
+		if err != nil {
+			atomic.AddInt32(&AddClusterFailCnt, 1)
+			log.Errorf("failed to add new cluster:%s, got error:%s\n", conf.Name, err.Error())
+			continue
 		}
+		log.Infof("succeed to add new cluster:%s", conf.Name)
 	}
 }
 
 func (c *Cluster) Close() {
+	if injector := c.getFaultInjector(); injector != nil {
+		injector.Stop()
+	}
 	c.forwarder.Close()
+	c.forwarder.Release()
 	c.closeAllConnections()
 }
 
-func (c *Cluster) addConnection(sn int32, conn *libnet.Conn) error {
+func (c *Cluster) addConnection(sn int32, conn *libnet.Conn, clientName string) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	if sn != c.conf.SN {
 		return errors.New("config is change, try again from:" + strconv.Itoa(int(sn)) + " to:" + strconv.Itoa(int(c.conf.SN)))
 	}
 	c.clientConns[conn.ID] = conn
+	c.connNames[conn.ID] = clientName
 	return nil
 }
 
@@ -362,6 +535,7 @@ func (c *Cluster) removeConnection(id int64) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	delete(c.clientConns, id)
+	delete(c.connNames, id)
 }
 
 func (c *Cluster) closeAndRemoveConnection(id int64) {
@@ -372,6 +546,7 @@ func (c *Cluster) closeAndRemoveConnection(id int64) {
 		return
 	}
 	delete(c.clientConns, id)
+	delete(c.connNames, id)
 	c.mutex.Unlock()
 	conn.Close()
 }
@@ -380,12 +555,55 @@ func (c *Cluster) closeAllConnections() {
 	c.mutex.Lock()
 	var curConns = c.clientConns
 	c.clientConns = make(map[int64]*libnet.Conn)
+	c.connNames = make(map[int64]string)
 	c.mutex.Unlock()
 	for _, conn := range curConns {
 		conn.Close()
 	}
 }
 
+// ConnInfo is the observability tag set surfaced for one live proxy-originated connection.
+type ConnInfo struct {
+	ID          int64    `json:"id"`
+	ClientName  string   `json:"client_name"`
+	RemoteAddr  string   `json:"remote_addr"`
+	BackendPool []string `json:"backend_pool"`
+}
+
+// nodeLister is the optional capability a proto.Forwarder can implement to report which backend
+// addresses it currently maintains connection streams to. Both shardForwarder and
+// proto/redis/cluster.Forwarder implement it; the minimal proto.Forwarder interface doesn't
+// require it so a future cache-type forwarder isn't forced to expose pool internals.
+type nodeLister interface {
+	Nodes() []string
+}
+
+// listConns snapshots ConnInfo for every connection currently tracked by the cluster, so an
+// operator can match a proxy connection id to a `CLIENT LIST` row on the Redis side, and see
+// which backend pool that connection's cluster is currently routing to.
+func (c *Cluster) listConns() []ConnInfo {
+	c.mutex.Lock()
+	var forwarder = c.forwarder
+	var curConns = c.clientConns
+	var curNames = c.connNames
+	c.mutex.Unlock()
+
+	var backendPool []string
+	if lister, ok := forwarder.(nodeLister); ok {
+		backendPool = lister.Nodes()
+	}
+
+	var infos = make([]ConnInfo, 0, len(curConns))
+	for id, conn := range curConns {
+		var info = ConnInfo{ID: id, ClientName: curNames[id], BackendPool: backendPool}
+		if conn != nil && conn.RemoteAddr() != nil {
+			info.RemoteAddr = conn.RemoteAddr().String()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
 func (c *Cluster) processConfChange(newConf *ClusterConfig) error {
 	newConf.ID = c.conf.ID
 	newConf.SN = genClusterSn()
@@ -400,6 +618,7 @@ func (c *Cluster) processConfChange(newConf *ClusterConfig) error {
 	c.forwarder = newForwarder
 	if newConf.CloseWhenChange {
 		c.clientConns = make(map[int64]*libnet.Conn)
+		c.connNames = make(map[int64]string)
 	}
 	c.conf = newConf
 	c.mutex.Unlock()
@@ -428,6 +647,65 @@ func (c *Cluster) getConf() *ClusterConfig {
 	return conf
 }
 
+func (c *Cluster) setListener(l net.Listener) {
+	c.mutex.Lock()
+	c.listener = l
+	c.mutex.Unlock()
+}
+
+func (c *Cluster) getListener() net.Listener {
+	c.mutex.Lock()
+	var l = c.listener
+	c.mutex.Unlock()
+	return l
+}
+
+func (c *Cluster) markDraining() {
+	c.mutex.Lock()
+	c.draining = true
+	c.mutex.Unlock()
+}
+
+func (c *Cluster) isDraining() bool {
+	c.mutex.Lock()
+	var draining = c.draining
+	c.mutex.Unlock()
+	return draining
+}
+
+// drainConnections waits up to timeout for every client connection to close on its own,
+// polling rather than blocking on each connection individually since the handlers that own
+// them run on their own goroutines. It returns false if the timeout elapsed with connections
+// still open, leaving the force-close to the caller.
+func (c *Cluster) drainConnections(timeout time.Duration) bool {
+	var deadline = time.Now().Add(timeout)
+	for {
+		c.mutex.Lock()
+		var remaining = len(c.clientConns)
+		c.mutex.Unlock()
+		if remaining == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (c *Cluster) getFaultInjector() *FaultInjector {
+	c.mutex.Lock()
+	var injector = c.faultInjector
+	c.mutex.Unlock()
+	return injector
+}
+
+func (c *Cluster) setFaultInjector(injector *FaultInjector) {
+	c.mutex.Lock()
+	c.faultInjector = injector
+	c.mutex.Unlock()
+}
+
 func compareConf(oldConf, newConf *ClusterConfig) (changed, valid bool) {
 	valid = (oldConf.ListenAddr == newConf.ListenAddr)
 	if ((oldConf.HashMethod != newConf.HashMethod) ||
@@ -436,6 +714,7 @@ func compareConf(oldConf, newConf *ClusterConfig) (changed, valid bool) {
 		(oldConf.CacheType != newConf.CacheType) ||
 		(oldConf.ListenProto != newConf.ListenProto) ||
 		(oldConf.RedisAuth != newConf.RedisAuth) ||
+		(oldConf.ReadStrategy != newConf.ReadStrategy) ||
 		(oldConf.DialTimeout != newConf.DialTimeout) ||
 		(oldConf.ReadTimeout != newConf.ReadTimeout) ||
 		(oldConf.WriteTimeout != newConf.WriteTimeout) ||
@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	libnet "overlord/pkg/net"
+)
+
+func TestAllocClusterIDReusesFreedSlot(t *testing.T) {
+	p := &Proxy{}
+
+	first, err := p.allocClusterID()
+	if err != nil {
+		t.Fatalf("allocClusterID: %v", err)
+	}
+	second, err := p.allocClusterID()
+	if err != nil {
+		t.Fatalf("allocClusterID: %v", err)
+	}
+	if first == second {
+		t.Fatalf("allocClusterID returned the same id twice before any release: %d", first)
+	}
+
+	// simulate removeCluster freeing `first` back up
+	p.freeClusterIDs = append(p.freeClusterIDs, first)
+
+	reused, err := p.allocClusterID()
+	if err != nil {
+		t.Fatalf("allocClusterID: %v", err)
+	}
+	if reused != first {
+		t.Fatalf("allocClusterID = %d, want the freed id %d to be reused before growing nextClusterID", reused, first)
+	}
+}
+
+func TestAllocClusterIDExhaustsMaxClusterCnt(t *testing.T) {
+	p := &Proxy{nextClusterID: MaxClusterCnt}
+	if _, err := p.allocClusterID(); err != ErrProxyMaxClusterCnt {
+		t.Fatalf("allocClusterID at MaxClusterCnt = %v, want ErrProxyMaxClusterCnt", err)
+	}
+}
+
+func TestRemovedClusters(t *testing.T) {
+	p := &Proxy{}
+	oldConfs := []*ClusterConfig{{Name: "a"}, {Name: "b"}}
+	newConfs := []*ClusterConfig{{Name: "a"}}
+
+	removed := p.removedClusters(newConfs, oldConfs)
+	if len(removed) != 1 || removed[0].Name != "b" {
+		t.Fatalf("removedClusters = %v, want just cluster %q", removed, "b")
+	}
+}
+
+func TestDrainConnections(t *testing.T) {
+	c := &Cluster{clientConns: map[int64]*libnet.Conn{1: nil, 2: nil}}
+
+	if c.drainConnections(50 * time.Millisecond) {
+		t.Fatal("drainConnections reported success with open connections still tracked")
+	}
+
+	c.mutex.Lock()
+	c.clientConns = map[int64]*libnet.Conn{}
+	c.mutex.Unlock()
+
+	if !c.drainConnections(time.Second) {
+		t.Fatal("drainConnections reported failure with no connections tracked")
+	}
+}
+
+type fakeNodeLister struct{ nodes []string }
+
+func (f *fakeNodeLister) AddRef() int32  { return 0 }
+func (f *fakeNodeLister) Release() int32 { return 0 }
+func (f *fakeNodeLister) Close() error   { return nil }
+func (f *fakeNodeLister) Nodes() []string {
+	return f.nodes
+}
+
+func TestListConnsIncludesBackendPool(t *testing.T) {
+	c := &Cluster{
+		forwarder:   &fakeNodeLister{nodes: []string{"10.0.0.1:6379", "10.0.0.2:6379"}},
+		clientConns: map[int64]*libnet.Conn{1: nil},
+		connNames:   map[int64]string{1: "pool-1"},
+	}
+
+	infos := c.listConns()
+	if len(infos) != 1 {
+		t.Fatalf("listConns returned %d entries, want 1", len(infos))
+	}
+	if infos[0].ClientName != "pool-1" {
+		t.Fatalf("ClientName = %q, want %q", infos[0].ClientName, "pool-1")
+	}
+	if len(infos[0].BackendPool) != 2 {
+		t.Fatalf("BackendPool = %v, want the forwarder's 2 nodes", infos[0].BackendPool)
+	}
+}
+
+func TestListConnsWithoutNodeListerLeavesBackendPoolEmpty(t *testing.T) {
+	c := &Cluster{
+		forwarder:   &shardForwarderStub{},
+		clientConns: map[int64]*libnet.Conn{1: nil},
+		connNames:   map[int64]string{},
+	}
+
+	infos := c.listConns()
+	if len(infos) != 1 {
+		t.Fatalf("listConns returned %d entries, want 1", len(infos))
+	}
+	if infos[0].BackendPool != nil {
+		t.Fatalf("BackendPool = %v, want nil for a forwarder that doesn't implement nodeLister", infos[0].BackendPool)
+	}
+}
+
+// shardForwarderStub is a proto.Forwarder that deliberately does not implement nodeLister, to
+// exercise listConns' type-assertion fallback.
+type shardForwarderStub struct{}
+
+func (s *shardForwarderStub) AddRef() int32  { return 0 }
+func (s *shardForwarderStub) Release() int32 { return 0 }
+func (s *shardForwarderStub) Close() error   { return nil }
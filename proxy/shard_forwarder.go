@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"overlord/proxy/hash"
+)
+
+// shardForwarder is the proto.Forwarder for the standalone (non Redis Cluster) cache types: it
+// shards conf.Servers across keys using conf.HashDistribution, and, when conf.PingAutoEject is
+// set, runs its own PING health check that ejects/includes servers from the picker as they fail
+// and recover.
+type shardForwarder struct {
+	// mu guards picker, since Rendezvous itself isn't safe for concurrent Pick and
+	// Eject/Include: pingLoop calls Eject/Include from its own goroutine while Forward calls
+	// Pick from every request-handling goroutine.
+	mu     sync.RWMutex
+	picker *hash.Rendezvous
+	pool   *nodeConnPool
+
+	refCnt int32
+
+	stopCh chan struct{}
+}
+
+// newShardForwarder builds a shardForwarder for conf. Only DistributionRendezvous is
+// implemented in this build; any other (or unset) HashDistribution is rejected rather than
+// silently falling back, since there is no ketama/modula ring to fall back to.
+func newShardForwarder(conf *ClusterConfig) (*shardForwarder, error) {
+	if conf.HashDistribution != DistributionRendezvous {
+		return nil, errors.New("proxy: HashDistribution:" + conf.HashDistribution + " is not implemented in this build")
+	}
+	var nodes = make([]hash.Node, len(conf.Servers))
+	for i, s := range conf.Servers {
+		nodes[i] = hash.Node{Name: s, Weight: 1}
+	}
+	var clientName = resolveClientName(conf.ClientName, conf.Name, "pool")
+	var f = &shardForwarder{
+		picker: hash.NewRendezvous(nodes),
+		pool:   newNodeConnPool(conf.NodeConnections, conf.CacheType, clientName),
+	}
+	f.pool.EnsureNodes(conf.Servers)
+	if conf.PingAutoEject {
+		f.stopCh = make(chan struct{})
+		go f.pingLoop(conf.Servers, conf.PingFailLimit)
+	}
+	return f, nil
+}
+
+// pingLoop periodically TCP-dials every server and ejects one from the picker once it has
+// failed PingFailLimit consecutive times, re-including it the moment a dial succeeds again. It
+// is the only thing in this build that drives Rendezvous.Eject/Include.
+func (f *shardForwarder) pingLoop(servers []string, failLimit int32) {
+	if failLimit <= 0 {
+		failLimit = 3
+	}
+	var fails = make(map[string]int32, len(servers))
+	var ticker = time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stopCh:
+			return
+		case <-ticker.C:
+			for _, addr := range servers {
+				if pingTCP(addr) {
+					fails[addr] = 0
+					f.mu.Lock()
+					f.picker.Include(addr)
+					f.mu.Unlock()
+					continue
+				}
+				fails[addr]++
+				if fails[addr] >= failLimit {
+					f.mu.Lock()
+					f.picker.Eject(addr)
+					f.mu.Unlock()
+				}
+			}
+		}
+	}
+}
+
+func pingTCP(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// Forward resolves which server should serve key.
+func (f *shardForwarder) Forward(key string) (string, error) {
+	f.mu.RLock()
+	var addr = f.picker.Pick(key)
+	f.mu.RUnlock()
+	if addr == "" {
+		return "", errors.New("proxy: no live node for key:" + key)
+	}
+	return addr, nil
+}
+
+// Nodes returns every backend address the forwarder currently maintains connection streams to,
+// for surfacing alongside each connection's observability tags.
+func (f *shardForwarder) Nodes() []string {
+	return f.pool.Nodes()
+}
+
+func (f *shardForwarder) AddRef() int32  { return atomic.AddInt32(&f.refCnt, 1) }
+func (f *shardForwarder) Release() int32 { return atomic.AddInt32(&f.refCnt, -1) }
+
+func (f *shardForwarder) Close() error {
+	if f.stopCh != nil {
+		select {
+		case <-f.stopCh:
+		default:
+			close(f.stopCh)
+		}
+	}
+	f.pool.Close()
+	return nil
+}